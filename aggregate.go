@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// BackendResponse struct untuk menangani respons dari backend
+type BackendResponse struct {
+	UUID     string `json:"uuid"`
+	Hostname string `json:"hostname"`
+	ExecTime string `json:"exec_time"`
+}
+
+// AggregatedResponse struct untuk respons dari client API
+type AggregatedResponse struct {
+	Responses     []BackendResponse `json:"responses"`
+	Backend1Count int               `json:"backend1_count"`
+	Backend2Count int               `json:"backend2_count"`
+	TotalTime     string            `json:"total_time"`
+}
+
+func (s *Server) aggregateHandler(w http.ResponseWriter, r *http.Request) {
+	requestStartTime := time.Now()
+	backend01 := "GoBackend01"
+	backend02 := "GoBackend02"
+	aggregateRequests.Add(1)
+
+	query := r.URL.Query()
+	countStr := query.Get("count")
+	if countStr == "" {
+		writeError(w, r, ErrMissingCount)
+		return
+	}
+
+	count, err := strconv.Atoi(countStr)
+	if err != nil {
+		writeError(w, r, ErrInvalidCount)
+		return
+	}
+
+	ctx := r.Context()
+	if timeout, ok := requestTimeout(r, s.opts.timeoutMin, s.opts.timeoutMax); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	backendURL := s.backend.Load()
+	if backendURL == nil {
+		writeError(w, r, ErrNoBackend)
+		return
+	}
+	fallbackURL := s.opts.fallback
+	var responses []BackendResponse
+	var backend1Count, backend2Count int
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	// Kegagalan dirangkum menjadi satu baris log per request, bukan satu per panggilan
+	var failovers, failures int
+	var failoverErr, failureErr error
+
+	totalStartTime := time.Now()
+
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			startTime := time.Now()
+			backendResp, err := s.callBackend(ctx, s.client, backendURL)
+			if err != nil && fallbackURL != nil && ctx.Err() == nil {
+				backendFailovers.Add(1)
+				mu.Lock()
+				failovers++
+				failoverErr = err
+				mu.Unlock()
+				backendResp, err = s.callBackend(ctx, s.fallbackClient, fallbackURL)
+			}
+			if err != nil {
+				mu.Lock()
+				failures++
+				failureErr = err
+				mu.Unlock()
+				return
+			}
+			endTime := time.Now()
+
+			mu.Lock()
+			responses = append(responses, BackendResponse{
+				UUID:     backendResp.UUID,
+				Hostname: backendResp.Hostname,
+				ExecTime: fmt.Sprintf("%d ms", endTime.Sub(startTime).Milliseconds()),
+			})
+			if backendResp.Hostname == backend01 {
+				backend1Count++
+			} else if backendResp.Hostname == backend02 {
+				backend2Count++
+			}
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	totalEndTime := time.Now()
+
+	if failovers > 0 {
+		s.opts.logger.Printf("Primary backend failed for %d of %d calls, failed over to fallback (last error: %v, request_id=%s)\n",
+			failovers, count, failoverErr, requestID(r.Context()))
+	}
+	if failures > 0 {
+		s.opts.logger.Printf("Backend calls failed for %d of %d calls (last error: %v, request_id=%s)\n",
+			failures, count, failureErr, requestID(r.Context()))
+	}
+
+	// // Calculate time taken for each request
+	// for i := range responses {
+	// 	responses[i].StartTime = responses[i].StartTime / int64(time.Millisecond)
+	// 	responses[i].EndTime = responses[i].EndTime / int64(time.Millisecond)
+	// }
+
+	aggregatedResponse := AggregatedResponse{
+		Responses:     responses,
+		Backend1Count: backend1Count,
+		Backend2Count: backend2Count,
+		TotalTime:     fmt.Sprintf("%d ms", totalEndTime.Sub(totalStartTime).Milliseconds()),
+	}
+
+	// ?__pretty=1 mengembalikan JSON yang di-indent untuk debugging di browser
+	var jsonResponse []byte
+	if query.Get("__pretty") == "1" {
+		jsonResponse, err = json.MarshalIndent(aggregatedResponse, "", "  ")
+	} else {
+		jsonResponse, err = json.Marshal(aggregatedResponse)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if s.opts.serverTiming {
+		upstream := totalEndTime.Sub(totalStartTime)
+		total := time.Since(requestStartTime)
+		w.Header().Set("Server-Timing", fmt.Sprintf("app;dur=%.1f, upstream;dur=%.1f, total;dur=%.1f",
+			msec(total-upstream), msec(upstream), msec(total)))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonResponse)
+}
+
+// msec mengubah durasi menjadi milidetik pecahan untuk Server-Timing
+func msec(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// callBackend melakukan satu GET ke backend dan mem-parsing responsnya;
+// respons 5xx dianggap gagal agar bisa dialihkan ke backend fallback
+func (s *Server) callBackend(ctx context.Context, client *http.Client, backendURL *url.URL) (BackendResponse, error) {
+	var backendResp BackendResponse
+	backendCalls.Add(1)
+	var timing callTiming
+	req := (&http.Request{
+		Method: http.MethodGet,
+		URL:    backendURL,
+		Header: make(http.Header),
+		Host:   backendURL.Host,
+	}).WithContext(timing.withTrace(ctx))
+	resp, err := client.Do(req)
+	if err != nil {
+		backendErrors.Add(1)
+		return backendResp, fmt.Errorf("calling backend: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		backendErrors.Add(1)
+		return backendResp, fmt.Errorf("calling backend: unexpected status %s", resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		backendErrors.Add(1)
+		return backendResp, fmt.Errorf("reading response body: %w", err)
+	}
+	timing.finish()
+	if s.opts.traceTiming {
+		s.opts.logger.Printf("Backend call timing %s: %s (request_id=%s)\n", backendURL.Host, &timing, requestID(ctx))
+	}
+
+	err = json.Unmarshal(body, &backendResp)
+	if err != nil {
+		backendErrors.Add(1)
+		return backendResp, fmt.Errorf("unmarshalling JSON response: %w", err)
+	}
+	return backendResp, nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// aggregate mengambil /aggregate dari srv dan men-decode respons JSON-nya
+func aggregate(t *testing.T, srv http.Handler, target string) AggregatedResponse {
+	t.Helper()
+	rec := serve(srv, http.MethodGet, target, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s: status = %d, body %s", target, rec.Code, rec.Body.String())
+	}
+	var response AggregatedResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decoding %s: %v", target, err)
+	}
+	return response
+}
+
+func TestAggregateFallback(t *testing.T) {
+	up := statusBackend(t, http.StatusOK)
+	down := statusBackend(t, http.StatusServiceUnavailable)
+	var logs bytes.Buffer
+
+	srv := newTestServer(t, WithBackend(down.URL), WithFallbackBackend(up.URL), WithLogger(log.New(&logs, "", 0)))
+	logs.Reset()
+	response := aggregate(t, srv, "/aggregate?count=5")
+	if len(response.Responses) != 5 || response.Backend1Count != 5 {
+		t.Errorf("got %d responses (%d from GoBackend01), want 5 from the fallback", len(response.Responses), response.Backend1Count)
+	}
+	if lines := strings.Count(logs.String(), "\n"); lines != 1 || !strings.Contains(logs.String(), "5 of 5 calls, failed over") {
+		t.Errorf("want one failover summary line, got:\n%s", logs.String())
+	}
+
+	srv = newTestServer(t, WithBackend(down.URL), WithLogger(log.New(&logs, "", 0)))
+	logs.Reset()
+	response = aggregate(t, srv, "/aggregate?count=3")
+	if len(response.Responses) != 0 {
+		t.Errorf("without fallback: got %d responses, want 0", len(response.Responses))
+	}
+	if lines := strings.Count(logs.String(), "\n"); lines != 1 || !strings.Contains(logs.String(), "Backend calls failed for 3 of 3 calls") {
+		t.Errorf("want one failure summary line, got:\n%s", logs.String())
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultBackendURL dipakai jika -backend, BACKEND_URL, dan BACKEND_URL_FILE tidak di-set
+const defaultBackendURL = "http://localhost:8081/uuid"
+
+// Nilai flag -backend dan apakah flag itu di-set secara eksplisit
+var (
+	backendFlag    string
+	backendFlagSet bool
+)
+
+// defaultBackend mengembalikan default flag -backend: BACKEND_URL jika ada
+func defaultBackend() string {
+	if value, ok := os.LookupEnv("BACKEND_URL"); ok {
+		return value
+	}
+	return defaultBackendURL
+}
+
+// loadBackendURL menentukan dan memvalidasi URL backend. Flag -backend yang
+// di-set eksplisit menang atas BACKEND_URL_FILE, yang menang atas BACKEND_URL.
+// Nilai kosong diperbolehkan dan menghasilkan nil.
+func loadBackendURL() (*url.URL, error) {
+	raw := backendFlag
+	if path := os.Getenv("BACKEND_URL_FILE"); path != "" && !backendFlagSet {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading BACKEND_URL_FILE: %w", err)
+		}
+
+		raw = strings.TrimSpace(string(data))
+		if raw == "" {
+			return nil, fmt.Errorf("BACKEND_URL_FILE %s is empty", path)
+		}
+	}
+
+	if raw == "" {
+		return nil, nil
+	}
+	return validateBackendURL(raw)
+}
+
+// validateBackendURL memastikan URL backend memakai http/https dan punya host,
+// atau memakai unix dengan path socket absolut
+func validateBackendURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadBackendURL, err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+		if u.Hostname() == "" {
+			return nil, fmt.Errorf("%w: no host", ErrBadBackendURL)
+		}
+	case "unix":
+		if socket, _ := unixSocket(u); u.Host != "" || !strings.HasPrefix(socket, "/") {
+			return nil, fmt.Errorf("%w: unix backends need an absolute socket path, e.g. unix:///run/backend.sock:/uuid", ErrBadBackendURL)
+		}
+	default:
+		return nil, fmt.Errorf("%w: scheme must be http, https or unix, got %q", ErrBadBackendURL, u.Scheme)
+	}
+	return u, nil
+}
+
+// unixSocket memisahkan URL backend unix:///path/ke.sock:/uuid menjadi path
+// socket dan path request HTTP; tanpa ":/" path request-nya "/"
+func unixSocket(u *url.URL) (socket, path string) {
+	if i := strings.Index(u.Path, ":/"); i >= 0 {
+		return u.Path[:i], u.Path[i+1:]
+	}
+	return u.Path, "/"
+}
+
+// checkPinnedIP menolak -backend-ip untuk backend unix karena tidak ada alamat TCP yang dipatok
+func checkPinnedIP(u *url.URL, pinnedIP string) error {
+	if u != nil && u.Scheme == "unix" && pinnedIP != "" {
+		return fmt.Errorf("backend IP %s cannot be used with unix socket backend %s", pinnedIP, u)
+	}
+	return nil
+}
+
+// timeoutHeader adalah header yang dipakai client untuk meminta timeout backend (ms)
+const timeoutHeader = "X-Proxy-Timeout"
+
+// Batas bawah dan atas default untuk timeout yang diminta lewat timeoutHeader
+const (
+	defaultTimeoutMin = 100 * time.Millisecond
+	defaultTimeoutMax = 30 * time.Second
+)
+
+// requestTimeout membaca timeoutHeader dan membatasinya ke rentang min..max
+func requestTimeout(r *http.Request, min, max time.Duration) (time.Duration, bool) {
+	value := r.Header.Get(timeoutHeader)
+	if value == "" {
+		return 0, false
+	}
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || ms <= 0 {
+		return 0, false
+	}
+
+	// Dibandingkan sebelum dikalikan agar nilai besar tidak overflow menjadi negatif
+	if ms > max.Milliseconds() {
+		return max, true
+	}
+	timeout := time.Duration(ms) * time.Millisecond
+	if timeout < min {
+		timeout = min
+	}
+	if timeout > max {
+		timeout = max
+	}
+	return timeout, true
+}
+
+// poolOptions mengatur connection pool milik satu backend; nilai 0 berarti default Go
+type poolOptions struct {
+	maxIdleConnsPerHost int
+	idleConnTimeout     time.Duration
+}
+
+// newBackendClient membuat client dengan transport sendiri untuk satu backend,
+// opsional dengan IP yang dipatok. URL unix:// dilayani lewat unixTransport.
+func newBackendClient(pinnedIP string, pool poolOptions) (*http.Client, error) {
+	if pool.maxIdleConnsPerHost < 0 || pool.idleConnTimeout < 0 {
+		return nil, fmt.Errorf("backend pool settings must not be negative")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if pool.maxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = pool.maxIdleConnsPerHost
+	}
+	if pool.idleConnTimeout > 0 {
+		transport.IdleConnTimeout = pool.idleConnTimeout
+	}
+	transport.RegisterProtocol("unix", &unixTransport{base: transport.Clone()})
+	if pinnedIP != "" {
+		if net.ParseIP(pinnedIP) == nil {
+			return nil, fmt.Errorf("invalid backend IP %q", pinnedIP)
+		}
+
+		// Host header dan SNI tetap diambil dari URL backend,
+		// hanya alamat dial yang diganti dengan IP yang dipatok.
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			_, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			return dialer.DialContext(ctx, network, net.JoinHostPort(pinnedIP, port))
+		}
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// unixTransport meneruskan request unix:// sebagai HTTP biasa lewat socket-nya.
+// Setiap socket punya transport sendiri agar koneksi idle tidak tercampur saat
+// URL backend di-reload ke socket lain.
+type unixTransport struct {
+	base       *http.Transport
+	mu         sync.Mutex
+	transports map[string]*http.Transport
+}
+
+// RoundTrip menulis ulang URL request ke http://localhost/path dan mengirimnya lewat socket
+func (t *unixTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	socket, path := unixSocket(req.URL)
+	out := req.Clone(req.Context())
+	out.URL = &url.URL{Scheme: "http", Host: "localhost", Path: path, RawQuery: req.URL.RawQuery}
+	out.Host = "localhost"
+	return t.transport(socket).RoundTrip(out)
+}
+
+// transport mengembalikan transport untuk socket, dibuat saat pertama dipakai
+func (t *unixTransport) transport(socket string) *http.Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if tr, ok := t.transports[socket]; ok {
+		return tr
+	}
+	if t.transports == nil {
+		t.transports = make(map[string]*http.Transport)
+	}
+	tr := t.base.Clone()
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	tr.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", socket)
+	}
+	t.transports[socket] = tr
+	return tr
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// setBackendFlag mengatur nilai flag -backend selama satu test
+func setBackendFlag(t *testing.T, value string, set bool) {
+	t.Helper()
+	oldFlag, oldSet := backendFlag, backendFlagSet
+	backendFlag, backendFlagSet = value, set
+	t.Cleanup(func() { backendFlag, backendFlagSet = oldFlag, oldSet })
+}
+
+func TestLoadBackendURLPrecedence(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "backend")
+	if err := os.WriteFile(file, []byte("http://from-file.example/uuid\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		env     string
+		file    string
+		flag    string
+		flagSet bool
+		want    string
+	}{
+		{name: "default", want: defaultBackendURL},
+		{name: "env", env: "http://from-env.example/uuid", want: "http://from-env.example/uuid"},
+		{name: "file beats env", env: "http://from-env.example/uuid", file: file, want: "http://from-file.example/uuid"},
+		{name: "flag beats file and env", env: "http://from-env.example/uuid", file: file,
+			flag: "http://from-flag.example/uuid", flagSet: true, want: "http://from-flag.example/uuid"},
+		{name: "empty env disables backend", env: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BACKEND_URL", tt.env)
+			if tt.name == "default" {
+				os.Unsetenv("BACKEND_URL") // t.Setenv di atas memulihkan nilai asal setelah test
+			}
+			t.Setenv("BACKEND_URL_FILE", tt.file)
+			value := tt.flag
+			if !tt.flagSet {
+				value = defaultBackend()
+			}
+			setBackendFlag(t, value, tt.flagSet)
+
+			u, err := loadBackendURL()
+			if err != nil {
+				t.Fatalf("loadBackendURL: %v", err)
+			}
+			got := ""
+			if u != nil {
+				got = u.String()
+			}
+			if got != tt.want {
+				t.Errorf("backend = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidBackendURLRejectedAtStartup(t *testing.T) {
+	for _, raw := range []string{"ftp://backend.example", "http://", "backend.example/uuid", "unix://run/backend.sock", "unix:relative.sock", "http://[::1"} {
+		_, err := NewServer(WithBackend(raw))
+		if !errors.Is(err, ErrBadBackendURL) {
+			t.Errorf("NewServer(WithBackend(%q)) error = %v, want ErrBadBackendURL", raw, err)
+		}
+	}
+	if _, err := NewServer(WithBackend(""), WithFallbackBackend("ftp://x")); !errors.Is(err, ErrBadBackendURL) {
+		t.Errorf("invalid fallback: error = %v, want ErrBadBackendURL", err)
+	}
+}
+
+func TestEmptyBackendAnswers502(t *testing.T) {
+	srv := newTestServer(t)
+	if rec := serve(srv, http.MethodGet, "/aggregate?count=1", nil); rec.Code != http.StatusBadGateway {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadGateway)
+	}
+}
+
+func TestRequestTimeoutClamping(t *testing.T) {
+	min, max := 100*time.Millisecond, 30*time.Second
+	tests := []struct {
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{"", 0, false},
+		{"abc", 0, false},
+		{"0", 0, false},
+		{"-5", 0, false},
+		{"1.5", 0, false},
+		{"50", min, true},
+		{"2500", 2500 * time.Millisecond, true},
+		{"30000", max, true},
+		{"60000", max, true},
+		{"9223372036854775", max, true},
+		{"9223372036854775807", max, true},
+		{"99999999999999999999", 0, false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/aggregate", nil)
+		if tt.header != "" {
+			r.Header.Set(timeoutHeader, tt.header)
+		}
+		got, ok := requestTimeout(r, min, max)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s=%q: got %s, %t; want %s, %t", timeoutHeader, tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestUnixSocket(t *testing.T) {
+	tests := []struct {
+		raw, socket, path string
+	}{
+		{"unix:///run/backend.sock", "/run/backend.sock", "/"},
+		{"unix:///run/backend.sock:/uuid", "/run/backend.sock", "/uuid"},
+		{"unix:///run/backend.sock:/api/uuid?x=1", "/run/backend.sock", "/api/uuid"},
+	}
+	for _, tt := range tests {
+		u, err := validateBackendURL(tt.raw)
+		if err != nil {
+			t.Errorf("validateBackendURL(%q): %v", tt.raw, err)
+			continue
+		}
+		if socket, path := unixSocket(u); socket != tt.socket || path != tt.path {
+			t.Errorf("unixSocket(%q) = %q, %q; want %q, %q", tt.raw, socket, path, tt.socket, tt.path)
+		}
+	}
+}
+
+func TestUnixSocketBackend(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "backend.sock")
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets are not available: %v", err)
+	}
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/uuid" || r.URL.RawQuery != "v=1" {
+			http.Error(w, "unexpected request "+r.URL.String(), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, `{"uuid":"u","hostname":"GoBackend01","exec_time":"1ms"}`)
+	}))
+	ts.Listener.Close()
+	ts.Listener = ln
+	ts.Start()
+	defer ts.Close()
+
+	backend := "unix://" + socket + ":/uuid?v=1"
+	srv := newTestServer(t, WithBackend(backend))
+	if response := aggregate(t, srv, "/aggregate?count=2"); response.Backend1Count != 2 {
+		t.Errorf("got %d of 2 responses through the socket: %+v", response.Backend1Count, response)
+	}
+	if status, response := readyz(t, srv); status != http.StatusOK {
+		t.Errorf("/readyz: status = %d (%+v)", status, response)
+	}
+
+	if _, err := NewServer(WithBackend(backend), WithBackendIP("127.0.0.1")); err == nil {
+		t.Error("NewServer accepted -backend-ip with a unix socket backend")
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"expvar"
+	"fmt"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// backendTiming menjumlahkan durasi tiap fase panggilan backend dalam milidetik
+var backendTiming = expvar.NewMap("backend_timing_ms")
+
+// callTiming mencatat waktu tiap fase satu panggilan backend lewat httptrace;
+// mu diperlukan karena callback dial bisa berjalan di goroutine lain
+type callTiming struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	firstByte    time.Time
+	done         time.Time
+	reused       bool
+}
+
+// withTrace memasang ClientTrace pada ctx yang mengisi callTiming
+func (t *callTiming) withTrace(ctx context.Context) context.Context {
+	t.start = time.Now()
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { t.mark(&t.dnsStart) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { t.mark(&t.dnsDone) },
+		ConnectStart:      func(string, string) { t.mark(&t.connectStart) },
+		ConnectDone:       func(string, string, error) { t.mark(&t.connectDone) },
+		TLSHandshakeStart: func() { t.mark(&t.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { t.mark(&t.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.reused = info.Reused
+			t.mu.Unlock()
+		},
+		GotFirstResponseByte: func() { t.mark(&t.firstByte) },
+	})
+}
+
+// mark mengisi waktu sebuah fase dengan waktu sekarang
+func (t *callTiming) mark(at *time.Time) {
+	t.mu.Lock()
+	*at = time.Now()
+	t.mu.Unlock()
+}
+
+// phase mengembalikan durasi antara from dan to, 0 jika fase itu tidak terjadi
+func phase(from, to time.Time) time.Duration {
+	if from.IsZero() || to.IsZero() {
+		return 0
+	}
+	return to.Sub(from)
+}
+
+// finish menandai akhir panggilan dan menambahkan durasi tiap fase ke backendTiming
+func (t *callTiming) finish() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.done = time.Now()
+	backendTiming.AddFloat("dns", msec(phase(t.dnsStart, t.dnsDone)))
+	backendTiming.AddFloat("connect", msec(phase(t.connectStart, t.connectDone)))
+	backendTiming.AddFloat("tls", msec(phase(t.tlsStart, t.tlsDone)))
+	backendTiming.AddFloat("ttfb", msec(phase(t.start, t.firstByte)))
+	backendTiming.AddFloat("body", msec(phase(t.firstByte, t.done)))
+	backendTiming.AddFloat("total", msec(phase(t.start, t.done)))
+}
+
+// String memformat fase panggilan untuk log
+func (t *callTiming) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return fmt.Sprintf("dns=%.1fms connect=%.1fms tls=%.1fms ttfb=%.1fms body=%.1fms total=%.1fms reused=%t",
+		msec(phase(t.dnsStart, t.dnsDone)), msec(phase(t.connectStart, t.connectDone)),
+		msec(phase(t.tlsStart, t.tlsDone)), msec(phase(t.start, t.firstByte)),
+		msec(phase(t.firstByte, t.done)), msec(phase(t.start, t.done)), t.reused)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"expvar"
+	"log"
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+// timingMetric membaca satu fase dari backend_timing_ms, 0 jika belum pernah dicatat
+func timingMetric(name string) float64 {
+	if v, ok := backendTiming.Get(name).(*expvar.Float); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+func TestTraceTimingRecordsProxiedCall(t *testing.T) {
+	backend := statusBackend(t, http.StatusOK)
+	var logs bytes.Buffer
+	srv := newTestServer(t, WithBackend(backend.URL), WithTraceTiming(true), WithLogger(log.New(&logs, "", 0)))
+	logs.Reset()
+	ttfb, total := timingMetric("ttfb"), timingMetric("total")
+
+	aggregate(t, srv, "/aggregate?count=1")
+
+	line := regexp.MustCompile(`Backend call timing 127\.0\.0\.1:\d+: dns=[\d.]+ms connect=[\d.]+ms tls=[\d.]+ms ttfb=[\d.]+ms body=[\d.]+ms total=[\d.]+ms reused=(true|false) \(request_id=\w+\)`)
+	if !line.MatchString(logs.String()) {
+		t.Errorf("no backend timing line in log:\n%s", logs.String())
+	}
+	if got := timingMetric("ttfb"); got <= ttfb {
+		t.Errorf("backend_timing_ms ttfb = %f, want more than %f", got, ttfb)
+	}
+	if got := timingMetric("total"); got <= total {
+		t.Errorf("backend_timing_ms total = %f, want more than %f", got, total)
+	}
+}
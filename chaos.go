@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// faultHeader menandai respons yang terkena fault injection
+const faultHeader = "X-Fault-Injected"
+
+// faultRule adalah fault yang disuntikkan ke request dengan path berawalan prefix
+type faultRule struct {
+	prefix       string
+	latency      time.Duration
+	jitter       time.Duration
+	errorPercent float64
+	status       int
+}
+
+// faultInjector menyimpan aturan fault dan status aktifnya yang bisa diubah lewat /admin/chaos
+type faultInjector struct {
+	rules   []faultRule
+	enabled atomic.Bool
+}
+
+// ChaosRule struct untuk satu aturan pada respons /admin/chaos
+type ChaosRule struct {
+	Prefix       string  `json:"prefix"`
+	Latency      string  `json:"latency"`
+	Jitter       string  `json:"jitter"`
+	ErrorPercent float64 `json:"error_percent"`
+	Status       int     `json:"status"`
+}
+
+// ChaosResponse struct untuk respons /admin/chaos
+type ChaosResponse struct {
+	Enabled bool        `json:"enabled"`
+	Rules   []ChaosRule `json:"rules"`
+}
+
+// parseFaultRules mem-parsing aturan yang dipisah titik koma, masing-masing
+// "prefix:key=value,...", mis. "/aggregate:latency=200ms,jitter=50ms,error=10,status=502"
+func parseFaultRules(value string) ([]faultRule, error) {
+	var rules []faultRule
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		prefix, params, _ := strings.Cut(entry, ":")
+		if !strings.HasPrefix(prefix, "/") {
+			return nil, fmt.Errorf("invalid fault rule %q, expected /prefix:key=value,...", entry)
+		}
+		rule := faultRule{prefix: prefix, status: http.StatusServiceUnavailable}
+		for _, param := range strings.Split(params, ",") {
+			if param == "" {
+				continue
+			}
+			key, raw, _ := strings.Cut(param, "=")
+			var err error
+			switch key {
+			case "latency":
+				rule.latency, err = time.ParseDuration(raw)
+			case "jitter":
+				rule.jitter, err = time.ParseDuration(raw)
+			case "error":
+				rule.errorPercent, err = strconv.ParseFloat(strings.TrimSuffix(raw, "%"), 64)
+			case "status":
+				rule.status, err = strconv.Atoi(raw)
+			default:
+				err = fmt.Errorf("unknown key %q", key)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid fault rule %q: %v", entry, err)
+			}
+		}
+		if rule.latency < 0 || rule.jitter < 0 {
+			return nil, fmt.Errorf("invalid fault rule %q: durations must not be negative", entry)
+		}
+		if rule.errorPercent < 0 || rule.errorPercent > 100 {
+			return nil, fmt.Errorf("invalid fault rule %q: error must be between 0 and 100", entry)
+		}
+		if rule.status < 400 || rule.status > 599 {
+			return nil, fmt.Errorf("invalid fault rule %q: status must be 4xx or 5xx", entry)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// match mengembalikan aturan dengan prefix terpanjang yang cocok dengan path
+func (f *faultInjector) match(path string) (faultRule, bool) {
+	var best faultRule
+	found := false
+	for _, rule := range f.rules {
+		if matchPrefix(path, rule.prefix) && len(rule.prefix) > len(best.prefix) {
+			best = rule
+			found = true
+		}
+	}
+	return best, found
+}
+
+// matchPrefix memeriksa apakah path berada di bawah prefix per segmen path,
+// jadi /aggregate cocok dengan /aggregate dan /aggregate/x tetapi tidak /aggregatefoo
+func matchPrefix(path, prefix string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	return len(path) == len(prefix) || strings.HasSuffix(prefix, "/") || path[len(prefix)] == '/'
+}
+
+// withFaults menyuntikkan latency dan error sesuai aturan yang cocok dengan path.
+// Route /admin/ tidak pernah terkena agar fault injection selalu bisa dimatikan.
+func (s *Server) withFaults(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.faults.enabled.Load() || strings.HasPrefix(r.URL.Path, "/admin/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		rule, ok := s.faults.match(r.URL.Path)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var injected []string
+		if delay := rule.latency; delay > 0 || rule.jitter > 0 {
+			if rule.jitter > 0 {
+				delay += time.Duration(rand.Int63n(int64(rule.jitter) + 1))
+			}
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-r.Context().Done():
+				timer.Stop()
+				return
+			}
+			injected = append(injected, "latency="+delay.Round(time.Millisecond).String())
+		}
+		if rule.errorPercent > 0 && rand.Float64()*100 < rule.errorPercent {
+			injected = append(injected, "error="+strconv.Itoa(rule.status))
+			w.Header().Set(faultHeader, strings.Join(injected, ", "))
+			http.Error(w, errorMessage(r, ErrFaultInjected), rule.status)
+			return
+		}
+		if len(injected) > 0 {
+			w.Header().Set(faultHeader, strings.Join(injected, ", "))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// chaosHandler menampilkan aturan fault injection; POST dengan ?enabled=true|false
+// menyalakan atau mematikannya
+func (s *Server) chaosHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		enabled, err := strconv.ParseBool(r.URL.Query().Get("enabled"))
+		if err != nil {
+			writeError(w, r, ErrInvalidEnabled)
+			return
+		}
+		if s.faults.enabled.Swap(enabled) != enabled {
+			if enabled {
+				s.opts.logger.Println("Fault injection enabled via /admin/chaos")
+			} else {
+				s.opts.logger.Println("Fault injection disabled via /admin/chaos")
+			}
+		}
+	}
+
+	response := ChaosResponse{Enabled: s.faults.enabled.Load(), Rules: make([]ChaosRule, 0, len(s.faults.rules))}
+	for _, rule := range s.faults.rules {
+		response.Rules = append(response.Rules, ChaosRule{
+			Prefix:       rule.prefix,
+			Latency:      rule.latency.String(),
+			Jitter:       rule.jitter.String(),
+			ErrorPercent: rule.errorPercent,
+			Status:       rule.status,
+		})
+	}
+	jsonResponse, err := json.Marshal(response)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(jsonResponse)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestChaosRequiresAdmin(t *testing.T) {
+	if _, err := NewServer(WithBackend(""), WithChaos(true, nil)); err == nil {
+		t.Error("NewServer accepted chaos mode without admin endpoints")
+	}
+	if _, err := NewServer(WithBackend(""), WithChaos(false, []faultRule{{prefix: "/", status: 503}})); err == nil {
+		t.Error("NewServer accepted fault rules without chaos mode")
+	}
+}
+
+func TestMatchPrefix(t *testing.T) {
+	tests := []struct {
+		path, prefix string
+		want         bool
+	}{
+		{"/aggregate", "/aggregate", true},
+		{"/aggregate/x", "/aggregate", true},
+		{"/aggregatefoo", "/aggregate", false},
+		{"/api/x", "/api/", true},
+		{"/anything", "/", true},
+		{"/uptime", "/aggregate", false},
+	}
+	for _, tt := range tests {
+		if got := matchPrefix(tt.path, tt.prefix); got != tt.want {
+			t.Errorf("matchPrefix(%q, %q) = %t, want %t", tt.path, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestChaosInjectsOnlyMatchingPrefix(t *testing.T) {
+	rules, err := parseFaultRules("/uptime:error=100,status=502")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newTestServer(t, WithChaos(true, rules), WithAdminConfig("admin", "pass", newFlagSet()))
+
+	rec := serve(srv, http.MethodGet, "/uptime", nil)
+	if rec.Code != http.StatusBadGateway || rec.Header().Get(faultHeader) != "error=502" {
+		t.Errorf("/uptime: status = %d, %s = %q, want 502 with error=502", rec.Code, faultHeader, rec.Header().Get(faultHeader))
+	}
+	for _, target := range []string{"/readyz", "/uptimefoo", "/whoami"} {
+		rec := serve(srv, http.MethodGet, target, nil)
+		if rec.Header().Get(faultHeader) != "" || rec.Code == http.StatusBadGateway {
+			t.Errorf("%s: fault injected (status %d, %s = %q)", target, rec.Code, faultHeader, rec.Header().Get(faultHeader))
+		}
+	}
+
+	// Header request tidak boleh bisa menyalakan atau memengaruhi fault injection
+	rec = serve(srv, http.MethodGet, "/readyz", http.Header{faultHeader: {"error=500"}, "X-Chaos": {"1"}})
+	if rec.Header().Get(faultHeader) != "" {
+		t.Errorf("/readyz with request headers: fault injected")
+	}
+
+	toggle := func(enabled string) {
+		req := httptest.NewRequest(http.MethodPost, "/admin/chaos?enabled="+enabled, nil)
+		req.SetBasicAuth("admin", "pass")
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"enabled":`+enabled) {
+			t.Fatalf("POST /admin/chaos?enabled=%s: status = %d, body %s", enabled, rec.Code, rec.Body.String())
+		}
+	}
+	toggle("false")
+	if rec := serve(srv, http.MethodGet, "/uptime", nil); rec.Code != http.StatusOK {
+		t.Errorf("/uptime after disabling: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	toggle("true")
+	if rec := serve(srv, http.MethodGet, "/uptime", nil); rec.Code != http.StatusBadGateway {
+		t.Errorf("/uptime after enabling: status = %d, want %d", rec.Code, http.StatusBadGateway)
+	}
+}
+
+func TestChaosLatency(t *testing.T) {
+	rules, err := parseFaultRules("/uptime:latency=20ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newTestServer(t, WithChaos(true, rules), WithAdminConfig("admin", "pass", newFlagSet()))
+	rec := serve(srv, http.MethodGet, "/uptime", nil)
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get(faultHeader), "latency=") {
+		t.Errorf("/uptime: status = %d, %s = %q, want 200 with latency", rec.Code, faultHeader, rec.Header().Get(faultHeader))
+	}
+}
+
+func TestParseFaultRulesRejectsInvalid(t *testing.T) {
+	for _, value := range []string{"x:error=1", "/x:error=101", "/x:status=200", "/x:latency=-1s", "/x:bogus=1"} {
+		if _, err := parseFaultRules(value); err == nil {
+			t.Errorf("parseFaultRules(%q) succeeded, want an error", value)
+		}
+	}
+}
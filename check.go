@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// checkDialTimeout adalah batas waktu koneksi TCP ke backend pada mode -check
+const checkDialTimeout = 2 * time.Second
+
+// checkResult menyimpan hasil satu pemeriksaan pada mode -check
+type checkResult struct {
+	Name    string
+	Err     error
+	Skipped bool
+}
+
+// runCheck melaporkan hasil pemeriksaan konfigurasi dari configChecks lalu mencoba
+// koneksi TCP atau unix socket ke backend tanpa membuka listener, mencetak
+// laporan ke out dan mengembalikan exit code
+func runCheck(out io.Writer, configChecks []checkResult, backendIP string, skipBackend bool) int {
+	results := append([]checkResult(nil), configChecks...)
+
+	u, urlErr := loadBackendURL()
+	if urlErr == nil {
+		urlErr = checkPinnedIP(u, backendIP)
+	}
+	network, dialAddr := "tcp", ""
+	switch {
+	case urlErr != nil || u == nil:
+	case u.Scheme == "unix":
+		network = "unix"
+		dialAddr, _ = unixSocket(u)
+	default:
+		dialAddr = backendDialAddr(u.Hostname(), u.Port(), u.Scheme, backendIP)
+	}
+	results = append(results, checkResult{Name: "backend URL", Err: urlErr})
+
+	// Backend kosong diperbolehkan, jadi tidak ada yang perlu di-dial
+	connect := checkResult{Name: "backend connect", Skipped: skipBackend || (urlErr == nil && u == nil)}
+	if !connect.Skipped {
+		if dialAddr == "" {
+			connect.Err = fmt.Errorf("no valid backend URL to connect to")
+		} else if conn, err := net.DialTimeout(network, dialAddr, checkDialTimeout); err != nil {
+			connect.Err = err
+		} else {
+			conn.Close()
+		}
+	}
+	results = append(results, connect)
+
+	failed := 0
+	for _, result := range results {
+		switch {
+		case result.Skipped:
+			fmt.Fprintf(out, "SKIP  %s\n", result.Name)
+		case result.Err != nil:
+			failed++
+			// errors.Join memisahkan kesalahan dengan baris baru, tiap kesalahan dicetak di barisnya sendiri
+			message := strings.ReplaceAll(result.Err.Error(), "\n", "\n      ")
+			fmt.Fprintf(out, "FAIL  %s: %s\n", result.Name, message)
+		default:
+			fmt.Fprintf(out, "OK    %s\n", result.Name)
+		}
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(out, "%d check(s) failed\n", failed)
+		return 1
+	}
+	fmt.Fprintln(out, "All checks passed")
+	return 0
+}
+
+// backendDialAddr menentukan alamat host:port yang akan di-dial untuk backend
+func backendDialAddr(host, port, scheme, pinnedIP string) string {
+	if pinnedIP != "" {
+		host = pinnedIP
+	}
+	if port == "" {
+		port = "80"
+		if scheme == "https" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(host, port)
+}
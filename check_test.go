@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// runMainEnv menandai proses anak yang harus menjalankan main() alih-alih test
+const runMainEnv = "SIMPLE_FE_TEST_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) == "1" {
+		// Argumen untuk main() ada setelah "--"
+		for i, arg := range os.Args {
+			if arg == "--" {
+				os.Args = append([]string{"fe"}, os.Args[i+1:]...)
+				break
+			}
+		}
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runMain menjalankan main() di proses terpisah dan mengembalikan exit code serta outputnya
+func runMain(t *testing.T, env []string, args ...string) (int, string) {
+	t.Helper()
+	// Batas waktu mencegah test menggantung jika konfigurasi yang salah ternyata membuat server jalan
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, os.Args[0], append([]string{"-test.run=^$", "--"}, args...)...)
+	cmd.Dir = t.TempDir()
+	cmd.Env = append(append(os.Environ(), runMainEnv+"=1"), env...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), out.String()
+	}
+	if err != nil {
+		t.Fatalf("running main: %v", err)
+	}
+	return 0, out.String()
+}
+
+func TestCheckPasses(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	code, out := runMain(t, nil, "-check", "-backend", "http://"+ln.Addr().String()+"/uuid")
+	if code != 0 || !strings.Contains(out, "OK    backend connect") || !strings.Contains(out, "All checks passed") {
+		t.Errorf("exit %d, output:\n%s", code, out)
+	}
+}
+
+func TestCheckReportsStartupErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		env  []string
+		args []string
+		fail string
+	}{
+		{"bad env value", []string{"FE_TCP_READ_BUFFER=abc"}, nil, "FAIL  flags and environment"},
+		{"unknown route timeout", nil, []string{"-route-timeouts", "/nope=5s"}, "FAIL  server options: route timeout configured for unknown route /nope"},
+		{"bad route timeout", nil, []string{"-route-timeouts", "nope"}, "FAIL  route timeouts"},
+		{"base path", nil, []string{"-base-path", "shop"}, "FAIL  server options: base path"},
+		{"maintenance allowlist", nil, []string{"-maintenance-allow", "bogus"}, "FAIL  maintenance allowlist"},
+		{"readiness checks", nil, []string{"-readiness-checks", "auth"}, "FAIL  readiness checks"},
+		{"readiness check URL", []string{"READINESS_CHECKS=auth=ftp://x"}, nil, "FAIL  server options: readiness check auth"},
+		{"fault rules", nil, []string{"-chaos", "-admin-user", "a", "-admin-password", "p", "-chaos-rules", "/x:error=200"}, "FAIL  fault rules"},
+		{"chaos without admin", nil, []string{"-chaos"}, "FAIL  server options: chaos mode requires"},
+		{"fallback URL", nil, []string{"-backend-fallback", "ftp://x"}, "FAIL  server options: fallback backend"},
+		{"expvar without auth", []string{"ENABLE_EXPVAR=1"}, nil, "FAIL  server options: /debug/vars requires"},
+		{"missing password file", nil, []string{"-admin-user", "a", "-admin-password", "p", "-admin-password-file", "/nonexistent"}, "FAIL  admin password file"},
+		{"backend URL", nil, []string{"-backend", "ftp://x"}, "FAIL  backend URL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"-check", "-check-skip-backend"}, tt.args...)
+			code, out := runMain(t, tt.env, args...)
+			if code != 1 || !strings.Contains(out, tt.fail) || !strings.Contains(out, "1 check(s) failed") {
+				t.Errorf("exit %d, want 1 with %q reported once, output:\n%s", code, tt.fail, out)
+			}
+
+			// Konfigurasi yang sama tanpa -check harus menggagalkan startup
+			code, out = runMain(t, tt.env, tt.args...)
+			if code != 1 || !strings.Contains(out, "Invalid configuration") {
+				t.Errorf("without -check: exit %d, want 1 with Invalid configuration, output:\n%s", code, out)
+			}
+		})
+	}
+}
+
+func TestCheckReportsAllErrors(t *testing.T) {
+	env := []string{"FE_TCP_READ_BUFFER=abc", "FE_TCP_WRITE_BUFFER=xyz"}
+	code, out := runMain(t, env, "-check", "-check-skip-backend",
+		"-base-path", "shop", "-route-timeouts", "/nope=5s", "-readiness-checks", "a=ftp://x")
+	for _, want := range []string{
+		"FE_TCP_READ_BUFFER",
+		"FE_TCP_WRITE_BUFFER",
+		"base path",
+		"unknown route /nope",
+		"readiness check a",
+		"2 check(s) failed",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output is missing %q:\n%s", want, out)
+		}
+	}
+	if code != 1 {
+		t.Errorf("exit %d, want 1", code)
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// envPrefix adalah prefix variabel environment untuk setiap flag
+const envPrefix = "FE_"
+
+// envName mengubah nama flag menjadi nama variabel environment, mis. backend-ip -> FE_BACKEND_IP
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// envFlags mencatat flag yang nilainya diambil dari variabel FE_* oleh applyEnv
+var envFlags = make(map[string]bool)
+
+// envOnlySettings adalah setting yang hanya dibaca dari environment, bukan dari flag
+var envOnlySettings = []string{"BACKEND_FALLBACK_URL", "BACKEND_URL", "BACKEND_URL_FILE", "ENABLE_EXPVAR", "EXPVAR_USER", "EXPVAR_PASSWORD"}
+
+// flagEnvFallback memetakan flag ke variabel environment yang menjadi default-nya
+var flagEnvFallback = map[string]string{
+	"backend":          "BACKEND_URL",
+	"backend-fallback": "BACKEND_FALLBACK_URL",
+	"readiness-checks": "READINESS_CHECKS",
+}
+
+// Asal nilai setting pada konfigurasi efektif
+const (
+	sourceDefault = "default"
+	sourceFlag    = "flag"
+	sourceEnv     = "env"
+	sourceFile    = "file"
+)
+
+// setting adalah satu nilai konfigurasi efektif beserta asalnya
+type setting struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// effectiveConfig mengumpulkan semua setting, urut berdasarkan nama, dengan nilai rahasia disamarkan.
+// Setting backend memakai backend yang sedang dipakai server, bukan nilai flag, karena
+// BACKEND_URL_FILE dan SIGHUP bisa mengubahnya.
+func effectiveConfig(fs *flag.FlagSet, backend *url.URL) []setting {
+	var settings []setting
+	fs.VisitAll(func(f *flag.Flag) {
+		source := sourceDefault
+		switch {
+		case envFlags[f.Name]:
+			source = envSource(envName(f.Name))
+		case isFlagSet(fs, f.Name):
+			source = sourceFlag
+		case flagEnvFallback[f.Name] != "":
+			source = envSource(flagEnvFallback[f.Name])
+		}
+		value := f.Value.String()
+		if f.Name == "backend" {
+			value = ""
+			if backend != nil {
+				value = backend.String()
+			}
+			if source == sourceDefault && os.Getenv("BACKEND_URL_FILE") != "" {
+				source = sourceFile
+			}
+		}
+		settings = append(settings, setting{Name: f.Name, Value: redact(f.Name, value), Source: source})
+	})
+	for _, name := range envOnlySettings {
+		settings = append(settings, setting{Name: name, Value: redact(name, os.Getenv(name)), Source: envSource(name)})
+	}
+
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Name < settings[j].Name
+	})
+	return settings
+}
+
+// envSource menentukan asal variabel environment: file .env, environment, atau default jika tidak ada
+func envSource(name string) string {
+	if dotenvKeys[name] {
+		return sourceFile
+	}
+	if _, ok := os.LookupEnv(name); ok {
+		return sourceEnv
+	}
+	return sourceDefault
+}
+
+// redact menyamarkan nilai rahasia: setting bernama password/secret/token
+// dan password di dalam URL
+func redact(name, value string) string {
+	if value == "" {
+		return value
+	}
+	lower := strings.ToLower(name)
+	for _, secret := range []string{"password", "secret", "token"} {
+		if strings.Contains(lower, secret) {
+			return "****"
+		}
+	}
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		return u.Redacted()
+	}
+	return value
+}
+
+// readSecretFile membaca rahasia dari file, tanpa spasi dan baris baru di ujungnya
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading secret file: %w", err)
+	}
+	secret := strings.TrimSpace(string(data))
+	if secret == "" {
+		return "", fmt.Errorf("secret file %s is empty", path)
+	}
+	return secret, nil
+}
+
+// parseRouteTimeouts mem-parsing daftar "path=durasi" yang dipisah koma, mis. "/aggregate=45s,/readyz=5s";
+// durasi 0 berarti route tersebut tidak dibatasi
+func parseRouteTimeouts(value string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		path, raw, ok := strings.Cut(entry, "=")
+		if !ok || !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("invalid route timeout %q, expected /path=duration", entry)
+		}
+		timeout, err := time.ParseDuration(raw)
+		if err != nil || timeout < 0 {
+			return nil, fmt.Errorf("invalid duration in route timeout %q", entry)
+		}
+		if _, dup := timeouts[path]; dup {
+			return nil, fmt.Errorf("duplicate route timeout for %s", path)
+		}
+		timeouts[path] = timeout
+	}
+	return timeouts, nil
+}
+
+// isFlagSet memeriksa apakah flag di-set di command line atau lewat variabel FE_*
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// applyEnv mengisi flag yang tidak di-set di command line dari variabel FE_*;
+// semua nilai yang tidak valid dilaporkan sekaligus
+func applyEnv(fs *flag.FlagSet) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	var errs []error
+	fs.VisitAll(func(f *flag.Flag) {
+		if set[f.Name] {
+			return
+		}
+		name := envName(f.Name)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return
+		}
+		if err := fs.Set(f.Name, value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid value %q for %s: %v", value, name, err))
+			return
+		}
+		envFlags[f.Name] = true
+	})
+	return errors.Join(errs...)
+}
+
+// usage mencetak bantuan flag beserta aturan variabel environment
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage of %s:\n", os.Args[0])
+	flag.PrintDefaults()
+	fmt.Fprintf(out, "\nEvery flag can also be set with an %s environment variable, e.g. -backend-ip as %s.\n", envPrefix, envName("backend-ip"))
+	fmt.Fprintln(out, "A flag given on the command line wins over its environment variable.")
+}
+
+// configVersion adalah versi format respons /admin/config
+const configVersion = 1
+
+// configHandler menampilkan konfigurasi efektif sebagai JSON, disusun ulang
+// setiap request agar perubahan lewat SIGHUP ikut terlihat
+func (s *Server) configHandler(w http.ResponseWriter, r *http.Request) {
+	body, err := json.MarshalIndent(struct {
+		Version  int       `json:"version"`
+		Settings []setting `json:"settings"`
+	}{configVersion, effectiveConfig(s.opts.adminFlags, s.backend.Load())}, "", "  ")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(body)
+}
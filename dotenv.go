@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// defaultEnvFile dimuat jika ada dan -env-file tidak di-set
+const defaultEnvFile = ".env"
+
+// dotenvKeys mencatat variabel yang di-set dari file .env
+var dotenvKeys = make(map[string]bool)
+
+// envFilePath menentukan file .env yang dipakai dan apakah file itu wajib ada
+func envFilePath(fs *flag.FlagSet) (string, bool) {
+	f := fs.Lookup("env-file")
+	if isFlagSet(fs, f.Name) {
+		return f.Value.String(), true
+	}
+	if value, ok := os.LookupEnv(envName(f.Name)); ok {
+		return value, true
+	}
+	return f.Value.String(), false
+}
+
+// loadEnvFile men-set variabel dari file .env tanpa menimpa environment yang sudah ada
+func loadEnvFile(path string, required bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !required {
+			return nil
+		}
+		return fmt.Errorf("reading env file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		key, value, ok, err := parseEnvLine(scanner.Text())
+		if err != nil {
+			return fmt.Errorf("%s:%d: %v", path, lineNo, err)
+		}
+		if !ok {
+			continue
+		}
+		if _, exists := os.LookupEnv(key); exists {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("%s:%d: %v", path, lineNo, err)
+		}
+		dotenvKeys[key] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading env file: %w", err)
+	}
+	return nil
+}
+
+// parseEnvLine mem-parsing satu baris KEY=VALUE; ok bernilai false untuk baris kosong/komentar
+func parseEnvLine(line string) (key, value string, ok bool, err error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false, nil
+	}
+	line = strings.TrimPrefix(line, "export ")
+
+	eq := strings.IndexByte(line, '=')
+	if eq < 0 {
+		return "", "", false, fmt.Errorf("expected KEY=VALUE")
+	}
+	key = strings.TrimSpace(line[:eq])
+	if !validEnvKey(key) {
+		return "", "", false, fmt.Errorf("invalid variable name %q", key)
+	}
+
+	raw := strings.TrimSpace(line[eq+1:])
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		value, err = unquoteEnvValue(raw)
+	case strings.HasPrefix(raw, "'"):
+		end := strings.IndexByte(raw[1:], '\'')
+		if end < 0 {
+			return "", "", false, fmt.Errorf("unterminated single-quoted value")
+		}
+		value, err = raw[1:end+1], checkTrailing(raw[end+2:])
+	default:
+		// Komentar setelah nilai tanpa kutip harus diawali spasi, mis. KEY=value # komentar
+		if i := strings.Index(raw, " #"); i >= 0 {
+			raw = raw[:i]
+		}
+		value = strings.TrimSpace(raw)
+	}
+	if err != nil {
+		return "", "", false, err
+	}
+	return key, value, true, nil
+}
+
+// unquoteEnvValue membaca nilai dalam tanda kutip ganda beserta escape-nya
+func unquoteEnvValue(raw string) (string, error) {
+	var b strings.Builder
+	for i := 1; i < len(raw); i++ {
+		c := raw[i]
+		switch c {
+		case '"':
+			return b.String(), checkTrailing(raw[i+1:])
+		case '\\':
+			i++
+			if i >= len(raw) {
+				return "", fmt.Errorf("unterminated double-quoted value")
+			}
+			switch raw[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\', '$':
+				b.WriteByte(raw[i])
+			default:
+				return "", fmt.Errorf("unknown escape sequence \\%c", raw[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated double-quoted value")
+}
+
+// checkTrailing memastikan setelah nilai berkutip hanya ada spasi atau komentar
+func checkTrailing(rest string) error {
+	rest = strings.TrimSpace(rest)
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return fmt.Errorf("unexpected characters after quoted value")
+	}
+	return nil
+}
+
+// validEnvKey memeriksa nama variabel: huruf, angka, dan underscore, tidak diawali angka
+func validEnvKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, c := range key {
+		switch {
+		case c == '_', c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseEnvLine(t *testing.T) {
+	tests := []struct {
+		line, key, value string
+		ok               bool
+	}{
+		{"", "", "", false},
+		{"   # comment", "", "", false},
+		{"KEY=value", "KEY", "value", true},
+		{"export KEY=value", "KEY", "value", true},
+		{" KEY = value # trailing comment", "KEY", "value", true},
+		{"KEY=value#not-a-comment", "KEY", "value#not-a-comment", true},
+		{"KEY=", "KEY", "", true},
+		{`KEY="line\nbreak \"quoted\" \$HOME"`, "KEY", "line\nbreak \"quoted\" $HOME", true},
+		{`KEY="a # b" # comment`, "KEY", "a # b", true},
+		{`KEY='raw \n $HOME'`, "KEY", `raw \n $HOME`, true},
+	}
+	for _, tt := range tests {
+		key, value, ok, err := parseEnvLine(tt.line)
+		if err != nil {
+			t.Errorf("parseEnvLine(%q): %v", tt.line, err)
+			continue
+		}
+		if key != tt.key || value != tt.value || ok != tt.ok {
+			t.Errorf("parseEnvLine(%q) = %q, %q, %t; want %q, %q, %t", tt.line, key, value, ok, tt.key, tt.value, tt.ok)
+		}
+	}
+}
+
+func TestParseEnvLineErrors(t *testing.T) {
+	for _, line := range []string{
+		"NOEQUALS",
+		"1KEY=value",
+		"KEY-NAME=value",
+		`KEY="unterminated`,
+		`KEY='unterminated`,
+		`KEY="bad \q escape"`,
+		`KEY="value" trailing`,
+	} {
+		if _, _, _, err := parseEnvLine(line); err == nil {
+			t.Errorf("parseEnvLine(%q) succeeded, want an error", line)
+		}
+	}
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	content := "# settings\nDOTENV_TEST_NEW=from-file\nDOTENV_TEST_EXISTING=from-file\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DOTENV_TEST_EXISTING", "from-env")
+	t.Setenv("DOTENV_TEST_NEW", "")
+	os.Unsetenv("DOTENV_TEST_NEW") // t.Setenv di atas memulihkan nilai asal setelah test
+
+	if err := loadEnvFile(path, true); err != nil {
+		t.Fatalf("loadEnvFile: %v", err)
+	}
+	if got := os.Getenv("DOTENV_TEST_NEW"); got != "from-file" {
+		t.Errorf("DOTENV_TEST_NEW = %q, want from-file", got)
+	}
+	if got := os.Getenv("DOTENV_TEST_EXISTING"); got != "from-env" {
+		t.Errorf("DOTENV_TEST_EXISTING = %q, the environment must win over the file", got)
+	}
+	if envSource("DOTENV_TEST_NEW") != sourceFile || envSource("DOTENV_TEST_EXISTING") != sourceEnv {
+		t.Errorf("sources = %s, %s; want %s, %s", envSource("DOTENV_TEST_NEW"), envSource("DOTENV_TEST_EXISTING"), sourceFile, sourceEnv)
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.env")
+	if err := loadEnvFile(missing, false); err != nil {
+		t.Errorf("optional missing file: %v", err)
+	}
+	if err := loadEnvFile(missing, true); err == nil {
+		t.Error("required missing file: no error")
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.env")
+	if err := os.WriteFile(bad, []byte("OK=1\nBROKEN\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadEnvFile(bad, true); err == nil || err.Error() != bad+":2: expected KEY=VALUE" {
+		t.Errorf("bad file error = %v, want the file and line number", err)
+	}
+}
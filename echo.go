@@ -0,0 +1,112 @@
+package main
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxEchoBody adalah jumlah byte body request yang dipantulkan oleh /debug/echo
+const maxEchoBody = 4096
+
+// echoSecretWords adalah potongan nama header yang nilainya selalu disamarkan di
+// /debug/echo, mis. Authorization, Cookie, X-Api-Key, X-Amz-Security-Token
+var echoSecretWords = []string{"auth", "cookie", "key", "session", "signature", "credential", "token", "secret", "password"}
+
+// secretHeader memeriksa apakah nama header menunjukkan nilai rahasia
+func secretHeader(name string) bool {
+	lower := strings.ToLower(name)
+	for _, word := range echoSecretWords {
+		if strings.Contains(lower, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// EchoTLS struct untuk detail TLS pada respons /debug/echo
+type EchoTLS struct {
+	Version     string `json:"version"`
+	CipherSuite string `json:"cipher_suite"`
+	ServerName  string `json:"server_name,omitempty"`
+	Protocol    string `json:"protocol,omitempty"`
+}
+
+// EchoResponse struct untuk respons /debug/echo
+type EchoResponse struct {
+	Method        string              `json:"method"`
+	URL           string              `json:"url"`
+	Proto         string              `json:"proto"`
+	Host          string              `json:"host"`
+	RemoteAddr    string              `json:"remote_addr"`
+	RequestID     string              `json:"request_id,omitempty"`
+	Headers       map[string][]string `json:"headers"`
+	TLS           *EchoTLS            `json:"tls,omitempty"`
+	Body          string              `json:"body,omitempty"`
+	BodyTruncated bool                `json:"body_truncated,omitempty"`
+}
+
+// echoHandler memantulkan request seperti yang diterima server sebagai JSON,
+// dengan header rahasia disamarkan dan body dipotong di maxEchoBody byte
+func echoHandler(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxEchoBody+1))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	response := EchoResponse{
+		Method:     r.Method,
+		URL:        requestURL(r),
+		Proto:      r.Proto,
+		Host:       r.Host,
+		RemoteAddr: r.RemoteAddr,
+		RequestID:  requestID(r.Context()),
+		Headers:    make(map[string][]string, len(r.Header)),
+	}
+	if len(body) > maxEchoBody {
+		body = body[:maxEchoBody]
+		response.BodyTruncated = true
+	}
+	response.Body = string(body)
+	for name, values := range r.Header {
+		redacted := make([]string, len(values))
+		for i, value := range values {
+			if secretHeader(name) {
+				redacted[i] = "****"
+			} else {
+				redacted[i] = redact(name, value)
+			}
+		}
+		response.Headers[name] = redacted
+	}
+	if r.TLS != nil {
+		response.TLS = &EchoTLS{
+			Version:     tls.VersionName(r.TLS.Version),
+			CipherSuite: tls.CipherSuiteName(r.TLS.CipherSuite),
+			ServerName:  r.TLS.ServerName,
+			Protocol:    r.TLS.NegotiatedProtocol,
+		}
+	}
+
+	jsonResponse, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(jsonResponse)
+}
+
+// requestURL menyusun ulang URL lengkap request dari scheme, Host, dan RequestURI
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.RequestURI
+}
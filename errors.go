@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error yang dipakai handler; writeError memetakannya ke status code HTTP
+var (
+	ErrMissingCount     = errors.New("count parameter is required")
+	ErrInvalidCount     = errors.New("invalid count parameter")
+	ErrNoBackend        = errors.New("no backend configured")
+	ErrBadBackendURL    = errors.New("invalid backend URL")
+	ErrMethodNotAllowed = errors.New("method not allowed")
+	ErrUnauthorized     = errors.New("unauthorized")
+	ErrForbidden        = errors.New("cross-site request rejected")
+	ErrNotFound         = errors.New("not found")
+	ErrTimeout          = errors.New("request timed out")
+	ErrMaintenance      = errors.New("down for maintenance, please retry later")
+	ErrFaultInjected    = errors.New("fault injected")
+	ErrInvalidEnabled   = errors.New("invalid enabled parameter")
+)
+
+// statusCode memetakan error ke status code HTTP, error lain dianggap 500
+func statusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrMissingCount), errors.Is(err, ErrInvalidCount), errors.Is(err, ErrInvalidEnabled):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrMethodNotAllowed):
+		return http.StatusMethodNotAllowed
+	case errors.Is(err, ErrNoBackend):
+		return http.StatusBadGateway
+	case errors.Is(err, ErrMaintenance), errors.Is(err, ErrTimeout):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeError menulis respons error sesuai statusCode, dengan request ID di header dan body
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	id := requestID(r.Context())
+	if id != "" {
+		w.Header().Set(requestIDHeader, id)
+	}
+	http.Error(w, errorMessage(r, err), statusCode(err))
+}
+
+// errorMessage membuat body respons error, ditambah request ID jika ada
+func errorMessage(r *http.Request, err error) string {
+	id := requestID(r.Context())
+	if id == "" {
+		return err.Error()
+	}
+	return fmt.Sprintf("%s (request_id=%s)", err.Error(), id)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestErrorResponsesCarryRequestID(t *testing.T) {
+	plain := newTestServer(t, WithExpvar("user", "pass"))
+	maintenance := newTestServer(t, WithBackend("http://backend.example/uuid"), WithMaintenance(true, "", nil))
+	tests := []struct {
+		name           string
+		srv            *Server
+		method, target string
+		status         int
+		bodyID         string // format request ID di body, default teks errorMessage
+	}{
+		{"missing count", plain, http.MethodGet, "/aggregate", http.StatusBadRequest, ""},
+		{"invalid count", plain, http.MethodGet, "/aggregate?count=x", http.StatusBadRequest, ""},
+		{"unauthorized", plain, http.MethodGet, "/debug/vars", http.StatusUnauthorized, ""},
+		{"not found", plain, http.MethodGet, "/nope", http.StatusNotFound, ""},
+		{"method not allowed", plain, http.MethodPost, "/uptime", http.StatusMethodNotAllowed, ""},
+		{"no backend", plain, http.MethodGet, "/aggregate?count=1", http.StatusBadGateway, ""},
+		{"maintenance", maintenance, http.MethodGet, "/aggregate?count=1", http.StatusServiceUnavailable, `"request_id":"%s"`},
+	}
+	for _, tt := range tests {
+		if tt.bodyID == "" {
+			tt.bodyID = "(request_id=%s)"
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(tt.srv, tt.method, tt.target, nil)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			id := rec.Header().Get(requestIDHeader)
+			if id == "" {
+				t.Fatalf("%s header is missing", requestIDHeader)
+			}
+			if want := fmt.Sprintf(tt.bodyID, id); !strings.Contains(rec.Body.String(), want) {
+				t.Errorf("body = %q, want it to contain %q", rec.Body.String(), want)
+			}
+
+			// Request ID dari client dipakai apa adanya
+			header := http.Header{}
+			header.Set(requestIDHeader, "client-id-1")
+			rec = serve(tt.srv, tt.method, tt.target, header)
+			if got := rec.Header().Get(requestIDHeader); got != "client-id-1" || !strings.Contains(rec.Body.String(), fmt.Sprintf(tt.bodyID, "client-id-1")) {
+				t.Errorf("client ID: header %q, body %q", got, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// healthCheckTimeout adalah batas waktu setiap pemeriksaan pada /readyz
+const healthCheckTimeout = 2 * time.Second
+
+// HealthCheck adalah satu pemeriksaan kesiapan; error berarti gagal
+type HealthCheck func(ctx context.Context) error
+
+// namedCheck adalah HealthCheck beserta namanya; kegagalan pemeriksaan optional
+// dilaporkan tetapi tidak membuat /readyz gagal
+type namedCheck struct {
+	name     string
+	check    HealthCheck
+	optional bool
+}
+
+// readinessURL adalah satu dependency dari -readiness-checks yang di-probe lewat HTTP GET
+type readinessURL struct {
+	name     string
+	raw      string
+	optional bool
+}
+
+// CheckResult struct untuk hasil satu pemeriksaan pada respons /readyz
+type CheckResult struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Optional bool   `json:"optional,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// ReadyResponse struct untuk respons /readyz
+type ReadyResponse struct {
+	Status        string        `json:"status"`
+	Uptime        string        `json:"uptime"`
+	UptimeSeconds float64       `json:"uptime_seconds"`
+	Checks        []CheckResult `json:"checks"`
+}
+
+// builtinChecks adalah nama pemeriksaan bawaan yang tidak boleh dipakai pemeriksaan lain
+var builtinChecks = []string{"backend", "maintenance"}
+
+// healthRegistry menyimpan pemeriksaan yang dijalankan oleh /readyz
+type healthRegistry struct {
+	checks []namedCheck
+}
+
+// register menambahkan pemeriksaan bernama ke registry
+func (h *healthRegistry) register(name string, check HealthCheck, optional bool) {
+	h.checks = append(h.checks, namedCheck{name: name, check: check, optional: optional})
+}
+
+// run menjalankan semua pemeriksaan secara paralel dan mengembalikan hasil sesuai urutan registrasi
+func (h *healthRegistry) run(ctx context.Context) (bool, []CheckResult) {
+	results := make([]CheckResult, len(h.checks))
+	var wg sync.WaitGroup
+	for i, c := range h.checks {
+		wg.Add(1)
+		go func(i int, c namedCheck) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+
+			results[i] = CheckResult{Name: c.name, Status: "ok", Optional: c.optional}
+			if err := c.check(checkCtx); err != nil {
+				results[i].Status = "fail"
+				results[i].Message = err.Error()
+			}
+		}(i, c)
+	}
+	wg.Wait()
+
+	ready := true
+	for _, result := range results {
+		if result.Status != "ok" && !result.Optional {
+			ready = false
+		}
+	}
+	return ready, results
+}
+
+// readyzHandler mengembalikan 200 jika semua pemeriksaan lolos, selain itu 503
+func (s *Server) readyzHandler(w http.ResponseWriter, r *http.Request) {
+	ready, results := s.health.run(r.Context())
+	uptime := time.Since(startTime)
+	response := ReadyResponse{
+		Status:        "ready",
+		Uptime:        uptime.Round(time.Second).String(),
+		UptimeSeconds: uptime.Seconds(),
+		Checks:        results,
+	}
+	status := http.StatusOK
+	if !ready {
+		response.Status = "not_ready"
+		status = http.StatusServiceUnavailable
+	}
+
+	jsonResponse, err := json.Marshal(response)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	w.Write(jsonResponse)
+}
+
+// checkBackend memeriksa apakah backend bisa melayani /aggregate: backend utama
+// menjawab 2xx, atau jika gagal, backend fallback yang menjawab 2xx. Tanpa backend
+// utama /aggregate selalu 502, jadi fallback tidak dianggap cukup.
+func (s *Server) checkBackend(ctx context.Context) error {
+	backendURL := s.backend.Load()
+	if backendURL == nil {
+		return ErrNoBackend
+	}
+	err := probeURL(ctx, s.client, backendURL)
+	if err == nil || s.opts.fallback == nil {
+		return err
+	}
+	if fallbackErr := probeURL(ctx, s.fallbackClient, s.opts.fallback); fallbackErr != nil {
+		return fmt.Errorf("primary: %v; fallback: %v", err, fallbackErr)
+	}
+	return nil
+}
+
+// checkURL membuat HealthCheck yang mem-probe target dengan HTTP GET
+func checkURL(client *http.Client, target *url.URL) HealthCheck {
+	return func(ctx context.Context) error {
+		return probeURL(ctx, client, target)
+	}
+}
+
+// parseReadinessChecks mem-parsing -readiness-checks: daftar "nama=url" yang dipisah
+// koma, dengan akhiran ";optional" untuk dependency yang tidak wajib
+func parseReadinessChecks(value string) ([]readinessURL, error) {
+	var checks []readinessURL
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		var check readinessURL
+		if raw, ok := strings.CutSuffix(entry, ";optional"); ok {
+			entry = raw
+			check.optional = true
+		}
+		name, raw, ok := strings.Cut(entry, "=")
+		if !ok || name == "" || raw == "" {
+			return nil, fmt.Errorf("invalid readiness check %q, expected name=url", entry)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate readiness check %s", name)
+		}
+		seen[name] = true
+		check.name = name
+		check.raw = raw
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// probeURL melakukan GET ke target dan mengharapkan status 2xx
+func probeURL(ctx context.Context, client *http.Client, target *url.URL) error {
+	req := (&http.Request{
+		Method: http.MethodGet,
+		URL:    target,
+		Header: make(http.Header),
+		Host:   target.Host,
+	}).WithContext(ctx)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
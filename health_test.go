@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseReadinessChecks(t *testing.T) {
+	got, err := parseReadinessChecks(" auth=http://auth.example/healthz, search=http://search.example/healthz;optional ,")
+	if err != nil {
+		t.Fatalf("parseReadinessChecks: %v", err)
+	}
+	want := []readinessURL{
+		{name: "auth", raw: "http://auth.example/healthz"},
+		{name: "search", raw: "http://search.example/healthz", optional: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseReadinessChecks = %+v, want %+v", got, want)
+	}
+
+	for _, value := range []string{"auth", "=http://auth.example", "auth=", "a=http://x.example,a=http://y.example"} {
+		if _, err := parseReadinessChecks(value); err == nil {
+			t.Errorf("parseReadinessChecks(%q) succeeded, want an error", value)
+		}
+	}
+}
+
+// statusBackend membuat backend uji yang selalu menjawab dengan status tertentu
+func statusBackend(t *testing.T, status int) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"uuid":"u","hostname":"GoBackend01","exec_time":"1ms"}`)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// readyz mengambil /readyz dari srv dan men-decode respons JSON-nya
+func readyz(t *testing.T, srv http.Handler) (int, ReadyResponse) {
+	t.Helper()
+	rec := serve(srv, http.MethodGet, "/readyz", nil)
+	var response ReadyResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decoding /readyz: %v\n%s", err, rec.Body.String())
+	}
+	return rec.Code, response
+}
+
+func TestReadyzBackendAndFallback(t *testing.T) {
+	up := statusBackend(t, http.StatusOK)
+	down := statusBackend(t, http.StatusServiceUnavailable)
+	tests := []struct {
+		name              string
+		primary, fallback string
+		status            int
+	}{
+		{"primary up", up.URL, "", http.StatusOK},
+		{"primary down", down.URL, "", http.StatusServiceUnavailable},
+		{"primary down, fallback up", down.URL, up.URL, http.StatusOK},
+		{"both down", down.URL, down.URL, http.StatusServiceUnavailable},
+		{"no primary, fallback up", "", up.URL, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t, WithBackend(tt.primary), WithFallbackBackend(tt.fallback))
+			status, response := readyz(t, srv)
+			if status != tt.status {
+				t.Errorf("status = %d, want %d (%+v)", status, tt.status, response)
+			}
+			if response.Uptime == "" {
+				t.Error("uptime is missing from /readyz")
+			}
+		})
+	}
+}
+
+func TestReadyzDependencies(t *testing.T) {
+	up := statusBackend(t, http.StatusOK)
+	down := statusBackend(t, http.StatusInternalServerError)
+
+	srv := newTestServer(t, WithBackend(up.URL),
+		WithReadinessURL("auth", up.URL, false),
+		WithReadinessURL("search", down.URL, true))
+	if status, response := readyz(t, srv); status != http.StatusOK {
+		t.Errorf("optional dependency down: status = %d, want %d (%+v)", status, http.StatusOK, response)
+	}
+
+	srv = newTestServer(t, WithBackend(up.URL),
+		WithReadinessURL("auth", down.URL, false),
+		WithReadinessURL("search", up.URL, true))
+	status, response := readyz(t, srv)
+	if status != http.StatusServiceUnavailable || response.Status != "not_ready" {
+		t.Fatalf("required dependency down: status = %d %q, want %d not_ready", status, response.Status, http.StatusServiceUnavailable)
+	}
+	want := map[string]string{"backend": "ok", "maintenance": "ok", "auth": "fail", "search": "ok"}
+	for _, result := range response.Checks {
+		if want[result.Name] != result.Status {
+			t.Errorf("check %s = %s, want %s", result.Name, result.Status, want[result.Name])
+		}
+		delete(want, result.Name)
+		if result.Name == "auth" && result.Message == "" {
+			t.Error("failed check auth has no message")
+		}
+	}
+	if len(want) > 0 {
+		t.Errorf("missing checks %v", want)
+	}
+}
+
+func TestHealthCheckNamesMustBeUnique(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	tests := map[string][]Option{
+		"builtin backend":     {WithReadinessURL("backend", "http://x.example", false)},
+		"builtin maintenance": {WithHealthCheck("maintenance", ok)},
+		"duplicate":           {WithHealthCheck("db", ok), WithReadinessURL("db", "http://x.example", false)},
+	}
+	for name, opts := range tests {
+		if _, err := NewServer(append([]Option{WithBackend("")}, opts...)...); err == nil {
+			t.Errorf("%s: NewServer succeeded, want an error", name)
+		}
+	}
+}
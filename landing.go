@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"time"
+)
+
+// LandingResponse struct untuk respons / saat -proxy-landing aktif
+type LandingResponse struct {
+	Service  string `json:"service"`
+	ServerID string `json:"server_id"`
+	Backend  string `json:"backend"`
+	Fallback string `json:"fallback,omitempty"`
+	Uptime   string `json:"uptime"`
+}
+
+// landingHandler menjawab / dengan status singkat: id server, backend, dan uptime
+func (s *Server) landingHandler(w http.ResponseWriter, r *http.Request) {
+	hostname, _ := os.Hostname()
+	response := LandingResponse{
+		Service:  "simple-golang-fe",
+		ServerID: hostname,
+		Uptime:   time.Since(startTime).Round(time.Second).String(),
+	}
+	if u := s.backend.Load(); u != nil {
+		response.Backend = redact("backend", u.String())
+	}
+	if s.opts.fallback != nil {
+		response.Fallback = redact("backend-fallback", s.opts.fallback.String())
+	}
+	jsonResponse, err := json.Marshal(response)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(jsonResponse)
+}
@@ -1,130 +1,169 @@
 package main
 
 import (
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
+	"context"
+	"flag"
+	"io"
 	"log"
-	"net/http"
-	"strconv"
-	"sync"
+	"net/url"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
-// BackendResponse struct untuk menangani respons dari backend
-type BackendResponse struct {
-	UUID     string `json:"uuid"`
-	Hostname string `json:"hostname"`
-	ExecTime string `json:"exec_time"`
-}
-
-// AggregatedResponse struct untuk respons dari client API
-type AggregatedResponse struct {
-	Responses     []BackendResponse `json:"responses"`
-	Backend1Count int               `json:"backend1_count"`
-	Backend2Count int               `json:"backend2_count"`
-	TotalTime     string            `json:"total_time"`
-}
-
 func main() {
-	http.HandleFunc("/aggregate", aggregateHandler)
-	log.Println("Starting client API server on :8082")
-	if err := http.ListenAndServe(":8082", nil); err != nil {
-		log.Fatalf("Could not start server: %s\n", err.Error())
+	flag.StringVar(&backendFlag, "backend", "", "backend URL to call, http(s)://host/path or unix:///path/to.sock:/path (default $BACKEND_URL or "+defaultBackendURL+")")
+	fallback := flag.String("backend-fallback", "", "fallback backend URL used when the primary fails (default $BACKEND_FALLBACK_URL)")
+	backendIP := flag.String("backend-ip", "", "connect to the backend at this IP instead of resolving its host (not for unix socket backends)")
+	maxIdle := flag.Int("backend-max-idle-conns-per-host", 0, "idle connections kept per host for the backend (0 = Go default)")
+	idleTimeout := flag.Duration("backend-idle-conn-timeout", 0, "how long idle backend connections are kept (0 = Go default)")
+	fallbackMaxIdle := flag.Int("backend-fallback-max-idle-conns-per-host", 0, "idle connections kept per host for the fallback backend (0 = Go default)")
+	fallbackIdleTimeout := flag.Duration("backend-fallback-idle-conn-timeout", 0, "how long idle fallback backend connections are kept (0 = Go default)")
+	timeoutMin := flag.Duration("backend-timeout-min", defaultTimeoutMin, "lower bound for the X-Proxy-Timeout request header")
+	timeoutMax := flag.Duration("backend-timeout-max", defaultTimeoutMax, "upper bound for the X-Proxy-Timeout request header")
+	adminUser := flag.String("admin-user", "", "basic auth user for /admin/config, /admin/maintenance and /debug/echo (enabled with an admin password)")
+	adminPassword := flag.String("admin-password", "", "basic auth password for the admin endpoints; prefer $"+envName("admin-password")+" or -admin-password-file, command-line flags are visible in ps")
+	adminPasswordFile := flag.String("admin-password-file", "", "read the admin basic auth password from this file")
+	tcpNoDelay := flag.Bool("tcp-nodelay", true, "set TCP_NODELAY on accepted connections")
+	tcpReadBuffer := flag.Int("tcp-read-buffer", 0, "socket receive buffer size in bytes for accepted connections (0 = OS default)")
+	tcpWriteBuffer := flag.Int("tcp-write-buffer", 0, "socket send buffer size in bytes for accepted connections (0 = OS default)")
+	basePath := flag.String("base-path", "", "serve all routes under this path prefix, e.g. /shop")
+	basePathOutside := flag.Bool("base-path-serve-outside", false, "with -base-path, also serve requests outside the prefix instead of returning 404")
+	requestTimeout := flag.Duration("request-timeout", 60*time.Second, "deadline for every handler, answered with 503 when exceeded (0 = no deadline)")
+	routeTimeouts := flag.String("route-timeouts", "", "per-route deadline overrides, e.g. /aggregate=45s,/readyz=5s (0 = no deadline)")
+	maintenance := flag.Bool("maintenance", false, "start in maintenance mode, answering /aggregate with 503")
+	maintenanceFile := flag.String("maintenance-file", "", "enter maintenance mode while this file exists (checked every 5s)")
+	maintenanceAllow := flag.String("maintenance-allow", "", "comma-separated IPs or CIDRs still served during maintenance")
+	fetchMetadata := flag.Bool("fetch-metadata-protection", false, "reject cross-site requests to /aggregate based on Sec-Fetch-* headers")
+	whoami := flag.Bool("whoami", true, "serve /whoami with instance and request identity (disable on public listeners)")
+	proxyLanding := flag.Bool("proxy-landing", false, "answer / with a JSON status (server id, backend, uptime) instead of 404")
+	readinessChecks := flag.String("readiness-checks", "", "extra /readyz dependencies as name=url, comma-separated; suffix ;optional for non-required ones (default $READINESS_CHECKS)")
+	chaos := flag.Bool("chaos", false, "enable fault injection for testing; never use in production")
+	chaosRules := flag.String("chaos-rules", "", "fault rules with -chaos, e.g. /aggregate:latency=200ms,jitter=50ms,error=10,status=502;/uptime:error=100")
+	traceTiming := flag.Bool("backend-trace-timing", false, "log DNS, connect, TLS, first-byte and body durations of every backend call")
+	serverTiming := flag.Bool("server-timing", false, "report frontend and upstream durations in a Server-Timing header")
+	check := flag.Bool("check", false, "validate the configuration and exit without serving")
+	checkSkipBackend := flag.Bool("check-skip-backend", false, "skip the backend TCP connect during -check")
+	flag.String("env-file", defaultEnvFile, "load environment variables from this file if present")
+	flag.Usage = usage
+	flag.Parse()
+	envErr := loadEnvFile(envFilePath(flag.CommandLine))
+	if envErr == nil {
+		envErr = applyEnv(flag.CommandLine)
 	}
-}
-
-func aggregateHandler(w http.ResponseWriter, r *http.Request) {
-	backend01 := "GoBackend01"
-	backend02 := "GoBackend02"
-
-	query := r.URL.Query()
-	countStr := query.Get("count")
-	if countStr == "" {
-		http.Error(w, "count parameter is required", http.StatusBadRequest)
-		return
+	backendFlagSet = isFlagSet(flag.CommandLine, "backend")
+	if !backendFlagSet {
+		backendFlag = defaultBackend()
 	}
-
-	count, err := strconv.Atoi(countStr)
-	if err != nil {
-		http.Error(w, "invalid count parameter", http.StatusBadRequest)
-		return
+	if !isFlagSet(flag.CommandLine, "backend-fallback") {
+		*fallback = os.Getenv("BACKEND_FALLBACK_URL")
+	}
+	if !isFlagSet(flag.CommandLine, "readiness-checks") {
+		*readinessChecks = os.Getenv("READINESS_CHECKS")
+	}
+	// Setiap sumber konfigurasi dicatat sebagai checkResult, sehingga -check melaporkan
+	// semua kesalahan yang juga akan menggagalkan startup
+	configChecks := []checkResult{{Name: "flags and environment", Err: envErr}}
+	routes, err := parseRouteTimeouts(*routeTimeouts)
+	configChecks = append(configChecks, checkResult{Name: "route timeouts", Err: err})
+	allow, err := parseIPAllowlist(*maintenanceAllow)
+	configChecks = append(configChecks, checkResult{Name: "maintenance allowlist", Err: err})
+	readiness, err := parseReadinessChecks(*readinessChecks)
+	configChecks = append(configChecks, checkResult{Name: "readiness checks", Err: err})
+	faultRules, err := parseFaultRules(*chaosRules)
+	configChecks = append(configChecks, checkResult{Name: "fault rules", Err: err})
+	if *adminPasswordFile != "" {
+		password, err := readSecretFile(*adminPasswordFile)
+		configChecks = append(configChecks, checkResult{Name: "admin password file", Err: err})
+		if err == nil {
+			*adminPassword = password
+		}
+	} else if isFlagSet(flag.CommandLine, "admin-password") && !envFlags["admin-password"] {
+		log.Printf("WARNING: -admin-password is visible in the process list, use %s or -admin-password-file instead\n", envName("admin-password"))
 	}
 
-	backendURL := "http://localhost:8081/uuid"
-	var responses []BackendResponse
-	var backend1Count, backend2Count int
-
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-
-	totalStartTime := time.Now()
+	opts := []Option{
+		WithBackendLoader(loadBackendURL),
+		WithFallbackBackend(*fallback),
+		WithBackendIP(*backendIP),
+		WithBackendPool(*maxIdle, *idleTimeout),
+		WithFallbackPool(*fallbackMaxIdle, *fallbackIdleTimeout),
+		WithTimeoutBounds(*timeoutMin, *timeoutMax),
+		WithTCPOptions(*tcpNoDelay, *tcpReadBuffer, *tcpWriteBuffer),
+		WithBasePath(*basePath, *basePathOutside),
+		WithRequestTimeout(*requestTimeout, routes),
+		WithMaintenance(*maintenance, *maintenanceFile, allow),
+		WithFetchMetadata(*fetchMetadata),
+		WithWhoami(*whoami),
+		WithProxyLanding(*proxyLanding),
+		WithChaos(*chaos, faultRules),
+		WithTraceTiming(*traceTiming),
+		WithServerTiming(*serverTiming),
+	}
+	for _, r := range readiness {
+		opts = append(opts, WithReadinessURL(r.name, r.raw, r.optional))
+	}
+	if os.Getenv("ENABLE_EXPVAR") != "" {
+		opts = append(opts, WithExpvar(os.Getenv("EXPVAR_USER"), os.Getenv("EXPVAR_PASSWORD")))
+	}
+	if *adminUser != "" || *adminPassword != "" {
+		opts = append(opts, WithAdminConfig(*adminUser, *adminPassword, flag.CommandLine))
+	}
+	if *check {
+		// Server dibuat tanpa Run, jadi alamat listen tidak pernah di-bind. URL backend
+		// diperiksa sendiri oleh runCheck, jadi tidak dimuat ulang di sini agar
+		// kesalahannya tidak dilaporkan dua kali.
+		opts = append(opts, WithLogger(log.New(io.Discard, "", 0)),
+			WithBackendLoader(func() (*url.URL, error) { return nil, nil }))
+	}
+	srv, err := NewServer(opts...)
+	configChecks = append(configChecks, checkResult{Name: "server options", Err: err})
 
-	for i := 0; i < count; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			startTime := time.Now()
-			resp, err := http.Get(backendURL)
-			if err != nil {
-				fmt.Println("Error while calling backend:", err)
-				return
-			}
-			defer resp.Body.Close()
+	if *check {
+		os.Exit(runCheck(os.Stdout, configChecks, *backendIP, *checkSkipBackend))
+	}
+	for _, c := range configChecks {
+		if c.Err != nil {
+			log.Fatalf("Invalid configuration: %s\n", c.Err.Error())
+		}
+	}
 
-			body, err := ioutil.ReadAll(resp.Body)
-			if err != nil {
-				fmt.Println("Error while reading response body:", err)
-				return
+	// Semua sinyal ditangani dalam satu loop: SIGHUP memuat ulang backend,
+	// restartSignal (SIGUSR2) menyerahkan listener ke proses baru, dan
+	// SIGINT/SIGTERM membatalkan ctx sehingga Run melakukan shutdown.
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM)
+	if restartSignal != nil {
+		signal.Notify(signals, restartSignal)
+	}
+	go func() {
+		for sig := range signals {
+			if sig == syscall.SIGHUP {
+				srv.Reload()
+				continue
 			}
-
-			endTime := time.Now()
-
-			var backendResp BackendResponse
-			err = json.Unmarshal(body, &backendResp)
-			if err != nil {
-				fmt.Println("Error while unmarshalling JSON response:", err)
+			if sig == restartSignal {
+				pid, err := srv.Restart()
+				if err != nil {
+					log.Println("Error while restarting, keeping current process:", err)
+					continue
+				}
+				log.Printf("Handed listener to new process %d, draining\n", pid)
+				signal.Stop(signals)
+				cancel()
 				return
 			}
-
-			mu.Lock()
-			responses = append(responses, BackendResponse{
-				UUID:     backendResp.UUID,
-				Hostname: backendResp.Hostname,
-				ExecTime: fmt.Sprintf("%d ms", endTime.Sub(startTime).Milliseconds()),
-			})
-			if backendResp.Hostname == backend01 {
-				backend1Count++
-			} else if backendResp.Hostname == backend02 {
-				backend2Count++
-			}
-			mu.Unlock()
-		}()
-	}
-
-	wg.Wait()
-
-	totalEndTime := time.Now()
-
-	// // Calculate time taken for each request
-	// for i := range responses {
-	// 	responses[i].StartTime = responses[i].StartTime / int64(time.Millisecond)
-	// 	responses[i].EndTime = responses[i].EndTime / int64(time.Millisecond)
-	// }
-
-	aggregatedResponse := AggregatedResponse{
-		Responses:     responses,
-		Backend1Count: backend1Count,
-		Backend2Count: backend2Count,
-		TotalTime:     fmt.Sprintf("%d ms", totalEndTime.Sub(totalStartTime).Milliseconds()),
-	}
-
-	jsonResponse, err := json.Marshal(aggregatedResponse)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+			log.Printf("Received %s, shutting down\n", sig)
+			signal.Stop(signals)
+			cancel()
+			return
+		}
+	}()
+
+	if err := srv.Run(ctx); err != nil {
+		log.Fatalf("Could not start server: %s\n", err.Error())
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(jsonResponse)
+	log.Println("Server stopped")
 }
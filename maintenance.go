@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Pengaturan maintenance mode
+const (
+	maintenancePollInterval = 5 * time.Second
+	maintenanceRetryAfter   = 120 // detik, dikirim di header Retry-After
+)
+
+// maintenanceState menyimpan status maintenance mode. manual diatur lewat flag
+// dan /admin/maintenance, sedangkan file mengikuti ada tidaknya sentinel file.
+type maintenanceState struct {
+	manual atomic.Bool
+	file   atomic.Bool
+}
+
+// active mengembalikan true jika maintenance mode aktif dari sumber mana pun
+func (m *maintenanceState) active() bool {
+	return m.manual.Load() || m.file.Load()
+}
+
+// MaintenanceResponse struct untuk respons /admin/maintenance
+type MaintenanceResponse struct {
+	Enabled bool `json:"enabled"`
+	Manual  bool `json:"manual"`
+	File    bool `json:"file"`
+}
+
+// MaintenanceError struct untuk respons 503 /aggregate selama maintenance mode
+type MaintenanceError struct {
+	Message    string `json:"message"`
+	RetryAfter int    `json:"retry_after"`
+	RequestID  string `json:"request_id,omitempty"`
+}
+
+// parseIPAllowlist mem-parsing daftar IP atau CIDR yang dipisah koma
+func parseIPAllowlist(value string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid allowlist entry %q: %w", entry, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowlist entry %q: %w", entry, err)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// withMaintenance menjawab 503 dengan Retry-After dan body JSON selama maintenance
+// mode aktif, kecuali untuk client yang IP-nya ada di allowlist
+func (s *Server) withMaintenance(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.maintenance.active() && !s.maintenanceBypass(r) {
+			writeMaintenance(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeMaintenance menulis respons 503 maintenance dalam JSON karena /aggregate adalah API JSON
+func writeMaintenance(w http.ResponseWriter, r *http.Request) {
+	jsonResponse, err := json.Marshal(MaintenanceError{
+		Message:    ErrMaintenance.Error(),
+		RetryAfter: maintenanceRetryAfter,
+		RequestID:  requestID(r.Context()),
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Retry-After", strconv.Itoa(maintenanceRetryAfter))
+	w.WriteHeader(statusCode(ErrMaintenance))
+	w.Write(jsonResponse)
+}
+
+// maintenanceBypass memeriksa apakah alamat client ada di allowlist maintenance
+func (s *Server) maintenanceBypass(r *http.Request) bool {
+	if len(s.opts.maintenanceAllow) == 0 {
+		return false
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range s.opts.maintenanceAllow {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkMaintenance membuat /readyz gagal selama maintenance mode aktif
+func (s *Server) checkMaintenance(ctx context.Context) error {
+	if s.maintenance.active() {
+		return ErrMaintenance
+	}
+	return nil
+}
+
+// pollMaintenanceFile memperbarui status maintenance dari sentinel file
+func (s *Server) pollMaintenanceFile() {
+	_, err := os.Stat(s.opts.maintenanceFile)
+	exists := err == nil
+	if s.maintenance.file.Swap(exists) != exists {
+		if exists {
+			s.opts.logger.Printf("Maintenance file %s found, entering maintenance mode\n", s.opts.maintenanceFile)
+		} else {
+			s.opts.logger.Printf("Maintenance file %s removed, leaving maintenance mode\n", s.opts.maintenanceFile)
+		}
+	}
+}
+
+// watchMaintenanceFile memeriksa sentinel file secara berkala sampai ctx dibatalkan
+func (s *Server) watchMaintenanceFile(ctx context.Context) {
+	ticker := time.NewTicker(maintenancePollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.pollMaintenanceFile()
+		}
+	}
+}
+
+// maintenanceHandler menampilkan status maintenance mode; POST dengan
+// ?enabled=true|false menyalakan atau mematikan mode manual
+func (s *Server) maintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		enabled, err := strconv.ParseBool(r.URL.Query().Get("enabled"))
+		if err != nil {
+			writeError(w, r, ErrInvalidEnabled)
+			return
+		}
+		if s.maintenance.manual.Swap(enabled) != enabled {
+			if enabled {
+				s.opts.logger.Println("Maintenance mode enabled via /admin/maintenance")
+			} else {
+				s.opts.logger.Println("Maintenance mode disabled via /admin/maintenance")
+			}
+		}
+	}
+
+	response := MaintenanceResponse{
+		Enabled: s.maintenance.active(),
+		Manual:  s.maintenance.manual.Load(),
+		File:    s.maintenance.file.Load(),
+	}
+	jsonResponse, err := json.Marshal(response)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(jsonResponse)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMaintenanceFlag(t *testing.T) {
+	backend := statusBackend(t, http.StatusOK)
+	srv := newTestServer(t, WithBackend(backend.URL), WithMaintenance(true, "", nil))
+
+	rec := serve(srv, http.MethodGet, "/aggregate?count=1", nil)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("/aggregate: status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "120" {
+		t.Errorf("Retry-After = %q, want 120", got)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	var body MaintenanceError
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding maintenance response %q: %v", rec.Body.String(), err)
+	}
+	if body.Message != ErrMaintenance.Error() || body.RetryAfter != 120 || body.RequestID != rec.Header().Get(requestIDHeader) {
+		t.Errorf("maintenance response = %+v", body)
+	}
+	if rec := serve(srv, http.MethodGet, "/uptime", nil); rec.Code != http.StatusOK {
+		t.Errorf("/uptime during maintenance: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if status, response := readyz(t, srv); status != http.StatusServiceUnavailable || response.Status == "ready" {
+		t.Errorf("/readyz during maintenance: status = %d (%s)", status, response.Status)
+	}
+}
+
+func TestMaintenanceAllowlist(t *testing.T) {
+	backend := statusBackend(t, http.StatusOK)
+	// httptest.NewRequest memakai RemoteAddr 192.0.2.1:1234
+	for _, tt := range []struct {
+		allow  string
+		status int
+	}{
+		{"192.0.2.1", http.StatusOK},
+		{"192.0.2.0/24", http.StatusOK},
+		{"10.0.0.0/8, 198.51.100.7", http.StatusServiceUnavailable},
+	} {
+		allow, err := parseIPAllowlist(tt.allow)
+		if err != nil {
+			t.Fatalf("parseIPAllowlist(%q): %v", tt.allow, err)
+		}
+		srv := newTestServer(t, WithBackend(backend.URL), WithMaintenance(true, "", allow))
+		if rec := serve(srv, http.MethodGet, "/aggregate?count=1", nil); rec.Code != tt.status {
+			t.Errorf("allow %q: status = %d, want %d", tt.allow, rec.Code, tt.status)
+		}
+	}
+
+	if _, err := parseIPAllowlist("192.0.2.1,not-an-ip"); err == nil {
+		t.Error("parseIPAllowlist accepted an invalid entry")
+	}
+}
+
+func TestMaintenanceFile(t *testing.T) {
+	backend := statusBackend(t, http.StatusOK)
+	file := filepath.Join(t.TempDir(), "maintenance")
+	srv := newTestServer(t, WithBackend(backend.URL), WithMaintenance(false, file, nil))
+
+	if rec := serve(srv, http.MethodGet, "/aggregate?count=1", nil); rec.Code != http.StatusOK {
+		t.Fatalf("without file: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	srv.pollMaintenanceFile()
+	if rec := serve(srv, http.MethodGet, "/aggregate?count=1", nil); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("with file: status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	if err := os.Remove(file); err != nil {
+		t.Fatal(err)
+	}
+	srv.pollMaintenanceFile()
+	if rec := serve(srv, http.MethodGet, "/aggregate?count=1", nil); rec.Code != http.StatusOK {
+		t.Errorf("after removing file: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestMaintenanceAdminToggle(t *testing.T) {
+	backend := statusBackend(t, http.StatusOK)
+	srv := newTestServer(t, WithBackend(backend.URL), WithAdminConfig("admin", "pass", newFlagSet()))
+
+	toggle := func(query string) int {
+		req := httptest.NewRequest(http.MethodPost, "/admin/maintenance"+query, nil)
+		req.SetBasicAuth("admin", "pass")
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if rec := serve(srv, http.MethodPost, "/admin/maintenance?enabled=true", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("without auth: status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if status := toggle("?enabled=maybe"); status != http.StatusBadRequest {
+		t.Errorf("invalid enabled: status = %d, want %d", status, http.StatusBadRequest)
+	}
+	if status := toggle("?enabled=true"); status != http.StatusOK {
+		t.Fatalf("enable: status = %d, want %d", status, http.StatusOK)
+	}
+	if rec := serve(srv, http.MethodGet, "/aggregate?count=1", nil); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("after enabling: status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	if status := toggle("?enabled=false"); status != http.StatusOK {
+		t.Fatalf("disable: status = %d, want %d", status, http.StatusOK)
+	}
+	if rec := serve(srv, http.MethodGet, "/aggregate?count=1", nil); rec.Code != http.StatusOK {
+		t.Errorf("after disabling: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"crypto/subtle"
+	"expvar"
+	"fmt"
+	"net/http"
+)
+
+// Variabel expvar untuk statistik request dan panggilan backend
+var (
+	aggregateRequests = expvar.NewInt("aggregate_requests")
+	backendCalls      = expvar.NewInt("backend_calls")
+	backendErrors     = expvar.NewInt("backend_errors")
+	backendFailovers  = expvar.NewInt("backend_failovers")
+)
+
+// metricNames adalah variabel expvar milik aplikasi yang ditampilkan di /debug/vars.
+// Variabel bawaan expvar (cmdline, memstats) sengaja tidak ikut karena cmdline
+// bisa memuat password yang diberikan lewat flag.
+var metricNames = []string{
+	"aggregate_requests",
+	"backend_calls",
+	"backend_errors",
+	"backend_failovers",
+	"backend_timing_ms",
+}
+
+// metricsHandler menampilkan variabel di metricNames dalam format yang sama dengan expvar.Handler
+func metricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	fmt.Fprintf(w, "{\n")
+	for i, name := range metricNames {
+		if i > 0 {
+			fmt.Fprintf(w, ",\n")
+		}
+		fmt.Fprintf(w, "%q: %s", name, expvar.Get(name))
+	}
+	fmt.Fprintf(w, "\n}\n")
+}
+
+// basicAuth mewajibkan HTTP basic auth dengan user dan password tertentu
+func basicAuth(user, password string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			u, p, ok := r.BasicAuth()
+			if !ok ||
+				subtle.ConstantTimeCompare([]byte(u), []byte(user)) != 1 ||
+				subtle.ConstantTimeCompare([]byte(p), []byte(password)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Basic realm="debug"`)
+				writeError(w, r, ErrUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Middleware membungkus handler dengan perilaku tambahan
+type Middleware func(http.Handler) http.Handler
+
+// Chain adalah urutan middleware; elemen pertama adalah lapisan terluar
+// dan dijalankan paling awal untuk setiap request
+type Chain []Middleware
+
+// NewChain membuat Chain dari middleware sesuai urutan yang diberikan
+func NewChain(middlewares ...Middleware) Chain {
+	return append(Chain(nil), middlewares...)
+}
+
+// Append mengembalikan Chain baru dengan middleware tambahan di lapisan dalam,
+// Chain asal tidak berubah sehingga aman dipakai ulang untuk beberapa route
+func (c Chain) Append(middlewares ...Middleware) Chain {
+	out := make(Chain, 0, len(c)+len(middlewares))
+	out = append(out, c...)
+	return append(out, middlewares...)
+}
+
+// Then membungkus h dengan semua middleware di Chain
+func (c Chain) Then(h http.Handler) http.Handler {
+	for i := len(c) - 1; i >= 0; i-- {
+		h = c[i](h)
+	}
+	return h
+}
+
+// allowMethods membatasi route ke method tertentu: OPTIONS dijawab 204 dan
+// method lain ditolak 405, keduanya dengan header Allow
+func allowMethods(methods ...string) Middleware {
+	allow := strings.Join(append(methods, http.MethodOptions), ", ")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions {
+				w.Header().Set("Allow", allow)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			for _, method := range methods {
+				if r.Method == method {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			w.Header().Set("Allow", allow)
+			writeError(w, r, ErrMethodNotAllowed)
+		})
+	}
+}
+
+// exactPath hanya meneruskan request dengan path persis sama dengan path,
+// path lain dijawab 404 apa pun method-nya
+func exactPath(path string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != path {
+				writeError(w, r, ErrNotFound)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// withBasePath memasang handler di bawah prefix basePath dan membuang prefix
+// itu sebelum routing. Path tanpa garis miring di akhir (mis. /shop) di-redirect
+// ke /shop/. Path di luar prefix mendapat 404, atau dilayani apa adanya jika
+// serveOutside bernilai true.
+func withBasePath(basePath string, serveOutside bool) Middleware {
+	return func(next http.Handler) http.Handler {
+		stripped := http.StripPrefix(basePath, next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == basePath:
+				target := basePath + "/"
+				if r.URL.RawQuery != "" {
+					target += "?" + r.URL.RawQuery
+				}
+				http.Redirect(w, r, target, http.StatusMovedPermanently)
+			case strings.HasPrefix(r.URL.Path, basePath+"/"):
+				stripped.ServeHTTP(w, r)
+			case serveOutside:
+				next.ServeHTTP(w, r)
+			default:
+				writeError(w, r, ErrNotFound)
+			}
+		})
+	}
+}
+
+// normalizeBasePath memastikan base path diawali "/" tanpa "/" di akhir;
+// "" dan "/" berarti tanpa base path
+func normalizeBasePath(basePath string) (string, error) {
+	if basePath == "" || basePath == "/" {
+		return "", nil
+	}
+	if !strings.HasPrefix(basePath, "/") {
+		return "", fmt.Errorf("base path %q must start with /", basePath)
+	}
+	if strings.ContainsAny(basePath, "?#") {
+		return "", fmt.Errorf("base path %q must not contain a query or fragment", basePath)
+	}
+	return strings.TrimRight(basePath, "/"), nil
+}
+
+// withTimeout memberi deadline pada context request. Jika handler belum selesai
+// saat deadline lewat, client mendapat 503 dan tulisan handler yang terlambat
+// dibuang. Nilai 0 berarti route tidak dibatasi, mis. untuk respons streaming.
+func withTimeout(timeout time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Body 503 dari TimeoutHandler tetap, jadi dibuat per request agar memuat request ID
+			http.TimeoutHandler(next, timeout, errorMessage(r, ErrTimeout)).ServeHTTP(w, r)
+		})
+	}
+}
+
+// withFetchMetadata menerapkan resource isolation policy berdasarkan header
+// Sec-Fetch-*: request same-origin, same-site, yang dibuat user langsung, dan
+// navigasi GET diizinkan, sedangkan request cross-site lain ditolak 403.
+// Browser lama yang tidak mengirim Sec-Fetch-Site tetap dilayani.
+func withFetchMetadata(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Sec-Fetch-Site") {
+		case "", "same-origin", "same-site", "none":
+			next.ServeHTTP(w, r)
+			return
+		}
+		dest := r.Header.Get("Sec-Fetch-Dest")
+		if r.Method == http.MethodGet && r.Header.Get("Sec-Fetch-Mode") == "navigate" &&
+			dest != "object" && dest != "embed" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		writeError(w, r, ErrForbidden)
+	})
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordOrder membuat middleware yang mencatat namanya ke order sebelum dan sesudah handler
+func recordOrder(name string, order *[]string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*order = append(*order, name+">")
+			next.ServeHTTP(w, r)
+			*order = append(*order, "<"+name)
+		})
+	}
+}
+
+// recordHandler membuat handler yang mencatat bahwa ia dipanggil
+func recordHandler(order *[]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*order = append(*order, "handler")
+	})
+}
+
+func TestChainOrder(t *testing.T) {
+	var order []string
+	h := NewChain(recordOrder("a", &order), recordOrder("b", &order)).
+		Append(recordOrder("c", &order)).
+		Then(recordHandler(&order))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	want := []string{"a>", "b>", "c>", "handler", "<c", "<b", "<a"}
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+}
+
+func TestChainAppendDoesNotModifyBase(t *testing.T) {
+	var order []string
+	base := NewChain(recordOrder("a", &order))
+	base.Append(recordOrder("b", &order))
+	base.Append(recordOrder("c", &order)).Then(recordHandler(&order)).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	want := []string{"a>", "c>", "handler", "<c", "<a"}
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+}
+
+func TestChainShortCircuit(t *testing.T) {
+	tests := []struct {
+		name   string
+		chain  func(order *[]string) Chain
+		req    func() *http.Request
+		status int
+		order  []string
+	}{
+		{
+			name: "method not allowed",
+			chain: func(order *[]string) Chain {
+				return NewChain(recordOrder("outer", order), allowMethods(http.MethodGet), recordOrder("inner", order))
+			},
+			req:    func() *http.Request { return httptest.NewRequest(http.MethodPost, "/", nil) },
+			status: http.StatusMethodNotAllowed,
+			order:  []string{"outer>", "<outer"},
+		},
+		{
+			name: "options",
+			chain: func(order *[]string) Chain {
+				return NewChain(allowMethods(http.MethodGet), recordOrder("inner", order))
+			},
+			req:    func() *http.Request { return httptest.NewRequest(http.MethodOptions, "/", nil) },
+			status: http.StatusNoContent,
+		},
+		{
+			name: "unauthorized",
+			chain: func(order *[]string) Chain {
+				return NewChain(basicAuth("user", "pass"), recordOrder("inner", order))
+			},
+			req:    func() *http.Request { return httptest.NewRequest(http.MethodGet, "/", nil) },
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "authorized",
+			chain: func(order *[]string) Chain {
+				return NewChain(basicAuth("user", "pass"), recordOrder("inner", order))
+			},
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.SetBasicAuth("user", "pass")
+				return r
+			},
+			status: http.StatusOK,
+			order:  []string{"inner>", "handler", "<inner"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var order []string
+			rec := httptest.NewRecorder()
+			tt.chain(&order).Then(recordHandler(&order)).ServeHTTP(rec, tt.req())
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if !reflect.DeepEqual(order, tt.order) {
+				t.Errorf("order = %v, want %v", order, tt.order)
+			}
+		})
+	}
+}
+
+// slowBackend membuat backend yang menjawab setelah delay atau saat request dibatalkan
+func slowBackend(t *testing.T, delay time.Duration) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		fmt.Fprintf(w, `{"uuid":"u","hostname":"GoBackend01","exec_time":"1ms"}`)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestRequestTimeout(t *testing.T) {
+	backend := slowBackend(t, 200*time.Millisecond)
+	srv := newTestServer(t, WithBackend(backend.URL), WithRequestTimeout(20*time.Millisecond, nil))
+
+	rec := serve(srv, http.MethodGet, "/aggregate?count=1", nil)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	id := rec.Header().Get(requestIDHeader)
+	if want := "request timed out (request_id=" + id + ")"; id == "" || !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("body = %q, want %q", rec.Body.String(), want)
+	}
+	if rec := serve(srv, http.MethodGet, "/uptime", nil); rec.Code != http.StatusOK {
+		t.Errorf("/uptime: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestRouteTimeoutOverrides(t *testing.T) {
+	backend := slowBackend(t, 50*time.Millisecond)
+	routes, err := parseRouteTimeouts("/aggregate=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newTestServer(t, WithBackend(backend.URL), WithRequestTimeout(10*time.Millisecond, routes))
+	if rec := serve(srv, http.MethodGet, "/aggregate?count=1", nil); rec.Code != http.StatusOK {
+		t.Errorf("exempt route: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	if _, err := NewServer(WithBackend(""), WithRequestTimeout(time.Second, map[string]time.Duration{"/missing": time.Second})); err == nil {
+		t.Error("NewServer accepted a timeout for an unknown route")
+	}
+}
+
+func TestBasePath(t *testing.T) {
+	srv := newTestServer(t, WithBasePath("/shop/", false))
+	tests := []struct {
+		target, location string
+		status           int
+	}{
+		{"/shop/uptime", "", http.StatusOK},
+		{"/shop", "/shop/", http.StatusMovedPermanently},
+		{"/shop?x=1", "/shop/?x=1", http.StatusMovedPermanently},
+		{"/shopping/uptime", "", http.StatusNotFound},
+		{"/uptime", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := serve(srv, http.MethodGet, tt.target, nil)
+		if rec.Code != tt.status || rec.Header().Get("Location") != tt.location {
+			t.Errorf("%s: status = %d, Location = %q; want %d, %q", tt.target, rec.Code, rec.Header().Get("Location"), tt.status, tt.location)
+		}
+	}
+
+	srv = newTestServer(t, WithBasePath("/shop", true))
+	if rec := serve(srv, http.MethodGet, "/uptime", nil); rec.Code != http.StatusOK {
+		t.Errorf("serve outside: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	for _, invalid := range []string{"shop", "/shop?x=1", "/shop#top"} {
+		if _, err := normalizeBasePath(invalid); err == nil {
+			t.Errorf("normalizeBasePath(%q) succeeded, want an error", invalid)
+		}
+	}
+}
+
+func TestFetchMetadata(t *testing.T) {
+	backend := statusBackend(t, http.StatusOK)
+	srv := newTestServer(t, WithBackend(backend.URL), WithFetchMetadata(true))
+	tests := []struct {
+		site, mode, dest string
+		status           int
+	}{
+		{"", "", "", http.StatusOK},
+		{"same-origin", "cors", "empty", http.StatusOK},
+		{"same-site", "cors", "empty", http.StatusOK},
+		{"none", "navigate", "document", http.StatusOK},
+		{"cross-site", "navigate", "document", http.StatusOK},
+		{"cross-site", "cors", "empty", http.StatusForbidden},
+		{"cross-site", "no-cors", "script", http.StatusForbidden},
+		{"cross-site", "navigate", "object", http.StatusForbidden},
+		{"cross-site", "navigate", "embed", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		header := http.Header{}
+		for name, value := range map[string]string{"Sec-Fetch-Site": tt.site, "Sec-Fetch-Mode": tt.mode, "Sec-Fetch-Dest": tt.dest} {
+			if value != "" {
+				header.Set(name, value)
+			}
+		}
+		if rec := serve(srv, http.MethodGet, "/aggregate?count=1", header); rec.Code != tt.status {
+			t.Errorf("site=%q mode=%q dest=%q: status = %d, want %d", tt.site, tt.mode, tt.dest, rec.Code, tt.status)
+		}
+	}
+
+	// Endpoint lain tidak dilindungi, mis. untuk health check dari origin lain
+	header := http.Header{"Sec-Fetch-Site": {"cross-site"}, "Sec-Fetch-Mode": {"cors"}}
+	if rec := serve(srv, http.MethodGet, "/uptime", header); rec.Code != http.StatusOK {
+		t.Errorf("/uptime cross-site: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
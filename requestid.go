@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// requestIDHeader adalah header untuk meneruskan dan mengembalikan request ID
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength membatasi panjang request ID yang diterima dari client
+const maxRequestIDLength = 128
+
+// requestIDKey adalah key context untuk menyimpan request ID
+type requestIDKey struct{}
+
+// withRequestID memastikan setiap request punya ID di context dan di header respons.
+// ID dari client dipakai jika valid, selain itu dibuat ID acak.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// requestID mengembalikan request ID dari context, string kosong jika tidak ada
+func requestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// newRequestID membuat request ID acak 16 byte dalam hex
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// validRequestID hanya menerima ID pendek berisi karakter ASCII yang terlihat
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// Variabel environment yang memberi tahu proses baru fd listener warisan dan fd pipe siap
+const (
+	listenerFDEnv = "LISTENER_FD"
+	readyFDEnv    = "READY_FD"
+)
+
+// restartReadyTimeout adalah batas waktu menunggu proses baru siap saat graceful restart
+const restartReadyTimeout = 10 * time.Second
+
+// listen membuka listener baru, atau memakai listener warisan jika proses ini
+// dijalankan oleh graceful restart
+func listen(addr string) (net.Listener, bool, error) {
+	fd, ok, err := inheritedFD(listenerFDEnv)
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		ln, err := net.Listen("tcp", addr)
+		return ln, false, err
+	}
+
+	f := os.NewFile(fd, "listener")
+	defer f.Close()
+	ln, err := net.FileListener(f)
+	if err != nil {
+		return nil, false, fmt.Errorf("using inherited listener: %w", err)
+	}
+	return ln, true, nil
+}
+
+// notifyReady memberi tahu proses lama bahwa proses ini sudah siap menerima koneksi
+func notifyReady() error {
+	fd, ok, err := inheritedFD(readyFDEnv)
+	if err != nil || !ok {
+		return err
+	}
+
+	f := os.NewFile(fd, "ready")
+	defer f.Close()
+	_, err = f.Write([]byte{1})
+	return err
+}
+
+// inheritedFD membaca nomor fd dari variabel environment lalu menghapusnya
+// agar tidak ikut diwariskan ke restart berikutnya
+func inheritedFD(name string) (uintptr, bool, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return 0, false, nil
+	}
+	os.Unsetenv(name)
+	fd, err := strconv.Atoi(value)
+	if err != nil || fd < 3 {
+		return 0, false, fmt.Errorf("invalid %s %q", name, value)
+	}
+	return uintptr(fd), true, nil
+}
+
+// Restart menjalankan proses baru yang mewarisi listener dan menunggu sampai
+// proses itu siap. Jika berhasil, pemanggil harus menghentikan server ini
+// agar request yang sedang berjalan selesai di proses lama.
+func (s *Server) Restart() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closing {
+		return 0, fmt.Errorf("server is shutting down")
+	}
+	tcpListener, ok := s.listener.(*net.TCPListener)
+	if !ok {
+		return 0, fmt.Errorf("listener does not support handoff")
+	}
+
+	listenerFile, err := tcpListener.File()
+	if err != nil {
+		return 0, fmt.Errorf("duplicating listener: %w", err)
+	}
+	defer listenerFile.Close()
+
+	readyRead, readyWrite, err := os.Pipe()
+	if err != nil {
+		return 0, err
+	}
+	defer readyRead.Close()
+
+	executable, err := os.Executable()
+	if err != nil {
+		readyWrite.Close()
+		return 0, err
+	}
+
+	// ExtraFiles[i] menjadi fd 3+i di proses baru
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Env = append(os.Environ(), listenerFDEnv+"=3", readyFDEnv+"=4")
+	cmd.ExtraFiles = []*os.File{listenerFile, readyWrite}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Start()
+	readyWrite.Close()
+	if err != nil {
+		return 0, fmt.Errorf("starting new process: %w", err)
+	}
+
+	ready := make(chan error, 1)
+	go func() {
+		_, err := readyRead.Read(make([]byte, 1))
+		ready <- err
+	}()
+
+	select {
+	case err = <-ready:
+	case <-time.After(restartReadyTimeout):
+		err = fmt.Errorf("timed out after %s", restartReadyTimeout)
+	}
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return 0, fmt.Errorf("new process did not become ready: %w", err)
+	}
+
+	pid := cmd.Process.Pid
+	cmd.Process.Release()
+	return pid, nil
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// restartSignal bernilai nil karena handoff listener hanya didukung di unix
+var restartSignal os.Signal
@@ -0,0 +1,11 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// restartSignal memicu graceful restart dengan handoff listener
+var restartSignal os.Signal = syscall.SIGUSR2
@@ -0,0 +1,544 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// shutdownTimeout adalah batas waktu menunggu request yang sedang berjalan saat shutdown
+const shutdownTimeout = 10 * time.Second
+
+// Server adalah client API yang mengagregasi respons dari backend
+type Server struct {
+	opts           options
+	client         *http.Client
+	fallbackClient *http.Client
+	backend        atomic.Pointer[url.URL]
+	handler        http.Handler
+	httpServer     *http.Server
+	listener       net.Listener
+	boundAddr      atomic.Pointer[string]
+	health         healthRegistry
+	routes         map[string]bool
+	maintenance    maintenanceState
+	faults         faultInjector
+
+	// mu menyerialkan Reload dan Shutdown agar tidak berjalan bersamaan
+	mu      sync.Mutex
+	closing bool
+}
+
+// options menyimpan konfigurasi yang diisi lewat Option
+type options struct {
+	addr             string
+	loadBackend      func() (*url.URL, error)
+	fallbackRaw      string
+	fallback         *url.URL
+	backendIP        string
+	pool             poolOptions
+	fallbackPool     poolOptions
+	timeoutMin       time.Duration
+	timeoutMax       time.Duration
+	serverTiming     bool
+	traceTiming      bool
+	whoami           bool
+	landing          bool
+	chaos            bool
+	faultRules       []faultRule
+	fetchMetadata    bool
+	expvar           bool
+	expvarUser       string
+	expvarPassword   string
+	tcp              tcpOptions
+	basePath         string
+	serveOutside     bool
+	adminUser        string
+	adminPassword    string
+	adminFlags       *flag.FlagSet
+	healthChecks     []namedCheck
+	readinessURLs    []readinessURL
+	requestTimeout   time.Duration
+	routeTimeouts    map[string]time.Duration
+	maintenanceOn    bool
+	maintenanceFile  string
+	maintenanceAllow []netip.Prefix
+	logger           *log.Logger
+}
+
+// Option mengubah konfigurasi Server yang dibuat NewServer
+type Option func(*options)
+
+// WithAddr mengatur alamat listen server
+func WithAddr(addr string) Option {
+	return func(o *options) { o.addr = addr }
+}
+
+// WithBackend mengatur URL backend tetap; string kosong berarti backend tidak dikonfigurasi
+func WithBackend(raw string) Option {
+	return WithBackendLoader(func() (*url.URL, error) {
+		if raw == "" {
+			return nil, nil
+		}
+		return validateBackendURL(raw)
+	})
+}
+
+// WithBackendLoader mengatur fungsi yang menentukan URL backend, dipanggil lagi saat Reload
+func WithBackendLoader(load func() (*url.URL, error)) Option {
+	return func(o *options) { o.loadBackend = load }
+}
+
+// WithFallbackBackend mengatur backend cadangan yang dipakai saat backend utama gagal;
+// string kosong berarti tanpa fallback
+func WithFallbackBackend(raw string) Option {
+	return func(o *options) { o.fallbackRaw = raw }
+}
+
+// WithBackendIP mematok koneksi backend ke IP tertentu
+func WithBackendIP(ip string) Option {
+	return func(o *options) { o.backendIP = ip }
+}
+
+// WithBackendPool mengatur connection pool untuk backend utama
+func WithBackendPool(maxIdleConnsPerHost int, idleConnTimeout time.Duration) Option {
+	return func(o *options) {
+		o.pool = poolOptions{maxIdleConnsPerHost: maxIdleConnsPerHost, idleConnTimeout: idleConnTimeout}
+	}
+}
+
+// WithFallbackPool mengatur connection pool untuk backend fallback
+func WithFallbackPool(maxIdleConnsPerHost int, idleConnTimeout time.Duration) Option {
+	return func(o *options) {
+		o.fallbackPool = poolOptions{maxIdleConnsPerHost: maxIdleConnsPerHost, idleConnTimeout: idleConnTimeout}
+	}
+}
+
+// WithTimeoutBounds mengatur batas timeout yang boleh diminta lewat X-Proxy-Timeout
+func WithTimeoutBounds(min, max time.Duration) Option {
+	return func(o *options) {
+		o.timeoutMin = min
+		o.timeoutMax = max
+	}
+}
+
+// WithTCPOptions mengatur TCP_NODELAY dan ukuran buffer socket untuk koneksi yang diterima;
+// ukuran buffer 0 berarti memakai default OS
+func WithTCPOptions(noDelay bool, readBuffer, writeBuffer int) Option {
+	return func(o *options) {
+		o.tcp = tcpOptions{noDelay: noDelay, readBuffer: readBuffer, writeBuffer: writeBuffer}
+	}
+}
+
+// WithBasePath memasang semua route di bawah prefix basePath; jika serveOutside
+// bernilai true, path tanpa prefix tetap dilayani alih-alih 404
+func WithBasePath(basePath string, serveOutside bool) Option {
+	return func(o *options) {
+		o.basePath = basePath
+		o.serveOutside = serveOutside
+	}
+}
+
+// WithServerTiming mengaktifkan header Server-Timing
+func WithServerTiming(enabled bool) Option {
+	return func(o *options) { o.serverTiming = enabled }
+}
+
+// WithWhoami mengatur apakah /whoami dipasang
+func WithWhoami(enabled bool) Option {
+	return func(o *options) { o.whoami = enabled }
+}
+
+// WithProxyLanding membuat / menjawab status singkat dalam JSON alih-alih 404
+func WithProxyLanding(enabled bool) Option {
+	return func(o *options) { o.landing = enabled }
+}
+
+// WithChaos mengaktifkan fault injection dengan aturan tertentu; aturan hanya
+// berlaku jika chaos bernilai true dan bisa dimatikan lewat /admin/chaos
+func WithChaos(enabled bool, rules []faultRule) Option {
+	return func(o *options) {
+		o.chaos = enabled
+		o.faultRules = rules
+	}
+}
+
+// WithTraceTiming mencatat fase setiap panggilan backend (DNS, connect, TLS, TTFB, body) ke log
+func WithTraceTiming(enabled bool) Option {
+	return func(o *options) { o.traceTiming = enabled }
+}
+
+// WithFetchMetadata menolak request cross-site ke /aggregate berdasarkan header Sec-Fetch-*
+func WithFetchMetadata(enabled bool) Option {
+	return func(o *options) { o.fetchMetadata = enabled }
+}
+
+// WithExpvar memasang /debug/vars dengan basic auth; user dan password wajib diisi
+func WithExpvar(user, password string) Option {
+	return func(o *options) {
+		o.expvar = true
+		o.expvarUser = user
+		o.expvarPassword = password
+	}
+}
+
+// WithAdminConfig memasang /admin/config yang menampilkan konfigurasi efektif dari flag di fs,
+// beserta /admin/maintenance, /debug/echo, dan /admin/chaos jika chaos aktif,
+// semuanya dilindungi basic auth dengan user dan password yang wajib diisi
+func WithAdminConfig(user, password string, fs *flag.FlagSet) Option {
+	return func(o *options) {
+		o.adminUser = user
+		o.adminPassword = password
+		o.adminFlags = fs
+	}
+}
+
+// WithHealthCheck menambahkan pemeriksaan bernama ke /readyz, selain pemeriksaan backend bawaan
+func WithHealthCheck(name string, check HealthCheck) Option {
+	return func(o *options) {
+		o.healthChecks = append(o.healthChecks, namedCheck{name: name, check: check})
+	}
+}
+
+// WithReadinessURL menambahkan dependency yang di-probe dengan HTTP GET oleh /readyz;
+// dependency optional dilaporkan tanpa membuat /readyz gagal
+func WithReadinessURL(name, raw string, optional bool) Option {
+	return func(o *options) {
+		o.readinessURLs = append(o.readinessURLs, readinessURL{name: name, raw: raw, optional: optional})
+	}
+}
+
+// WithRequestTimeout mengatur batas waktu default setiap handler dan override per route;
+// 0 berarti tanpa batas waktu
+func WithRequestTimeout(timeout time.Duration, routes map[string]time.Duration) Option {
+	return func(o *options) {
+		o.requestTimeout = timeout
+		o.routeTimeouts = routes
+	}
+}
+
+// WithMaintenance mengatur maintenance mode: enabled menyalakannya sejak start,
+// file adalah sentinel file yang diperiksa berkala (kosong berarti tidak dipakai),
+// dan allow berisi alamat client yang tetap dilayani selama maintenance
+func WithMaintenance(enabled bool, file string, allow []netip.Prefix) Option {
+	return func(o *options) {
+		o.maintenanceOn = enabled
+		o.maintenanceFile = file
+		o.maintenanceAllow = allow
+	}
+}
+
+// WithLogger mengatur logger untuk server
+func WithLogger(logger *log.Logger) Option {
+	return func(o *options) { o.logger = logger }
+}
+
+// NewServer membuat Server dan memvalidasi semua opsi di awal
+func NewServer(opts ...Option) (*Server, error) {
+	o := options{
+		addr:        ":8082",
+		loadBackend: func() (*url.URL, error) { return validateBackendURL(defaultBackendURL) },
+		timeoutMin:  defaultTimeoutMin,
+		timeoutMax:  defaultTimeoutMax,
+		tcp:         tcpOptions{noDelay: true},
+		whoami:      true,
+		logger:      log.New(os.Stderr, "", log.LstdFlags),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	// Kesalahan opsi dikumpulkan sampai akhir agar semua dilaporkan sekaligus, mis. oleh -check
+	var errs []error
+	if o.addr == "" {
+		errs = append(errs, fmt.Errorf("listen address is empty"))
+	}
+	if o.adminFlags != nil && (o.adminUser == "" || o.adminPassword == "") {
+		errs = append(errs, fmt.Errorf("/admin/config requires both an admin user and password"))
+	}
+	if o.expvar && (o.expvarUser == "" || o.expvarPassword == "") {
+		errs = append(errs, fmt.Errorf("/debug/vars requires both EXPVAR_USER and EXPVAR_PASSWORD"))
+	}
+	if o.tcp.readBuffer < 0 || o.tcp.writeBuffer < 0 {
+		errs = append(errs, fmt.Errorf("TCP buffer sizes must not be negative"))
+	}
+	basePath, err := normalizeBasePath(o.basePath)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	o.basePath = basePath
+	if o.requestTimeout < 0 {
+		errs = append(errs, fmt.Errorf("request timeout must not be negative"))
+	}
+	if len(o.faultRules) > 0 && !o.chaos {
+		errs = append(errs, fmt.Errorf("fault rules require chaos mode to be enabled"))
+	}
+	if o.chaos && o.adminFlags == nil {
+		errs = append(errs, fmt.Errorf("chaos mode requires the admin endpoints so it can be turned off at runtime"))
+	}
+	if o.timeoutMin > o.timeoutMax {
+		errs = append(errs, fmt.Errorf("backend timeout min %s is above max %s", o.timeoutMin, o.timeoutMax))
+	}
+	if o.fallbackRaw != "" {
+		fallback, err := validateBackendURL(o.fallbackRaw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("fallback backend: %w", err))
+		}
+		o.fallback = fallback
+	}
+
+	client, err := newBackendClient(o.backendIP, o.pool)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	// Fallback selalu punya transport sendiri: pool-nya terpisah dari backend
+	// utama dan IP yang dipatok hanya berlaku untuk backend utama.
+	var fallbackClient *http.Client
+	if o.fallback != nil {
+		fallbackClient, err = newBackendClient("", o.fallbackPool)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("fallback backend: %w", err))
+		}
+	}
+
+	checkNames := make(map[string]bool)
+	for _, name := range builtinChecks {
+		checkNames[name] = true
+	}
+	for _, name := range checkNamesOf(o.healthChecks, o.readinessURLs) {
+		if checkNames[name] {
+			errs = append(errs, fmt.Errorf("health check name %q is already in use", name))
+		}
+		checkNames[name] = true
+	}
+
+	readinessChecks := make([]namedCheck, 0, len(o.readinessURLs))
+	if len(o.readinessURLs) > 0 {
+		probeClient, err := newBackendClient("", poolOptions{})
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, r := range o.readinessURLs {
+			target, err := validateBackendURL(r.raw)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("readiness check %s: %w", r.name, err))
+				continue
+			}
+			readinessChecks = append(readinessChecks, namedCheck{name: r.name, check: checkURL(probeClient, target), optional: r.optional})
+		}
+	}
+
+	initialURL, err := o.loadBackend()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkPinnedIP(initialURL, o.backendIP); err != nil {
+		errs = append(errs, err)
+	}
+
+	s := &Server{opts: o, client: client, fallbackClient: fallbackClient}
+	s.backend.Store(initialURL)
+	s.maintenance.manual.Store(o.maintenanceOn)
+	s.faults.rules = o.faultRules
+	s.faults.enabled.Store(o.chaos)
+	if o.maintenanceFile != "" {
+		s.pollMaintenanceFile()
+	}
+	s.health.register("backend", s.checkBackend, false)
+	s.health.register("maintenance", s.checkMaintenance, false)
+	for _, c := range append(o.healthChecks, readinessChecks...) {
+		s.health.register(c.name, c.check, c.optional)
+	}
+
+	mux := http.NewServeMux()
+	readOnly := NewChain(allowMethods(http.MethodGet, http.MethodHead))
+	if o.landing {
+		// Pola "/" menangkap semua path, jadi path lain harus 404 sebelum allowMethods
+		mux.Handle("/", exactPath("/")(s.route("/", readOnly, http.HandlerFunc(s.landingHandler))))
+	} else {
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) { writeError(w, r, ErrNotFound) })
+	}
+	api := readOnly
+	if o.fetchMetadata {
+		api = api.Append(withFetchMetadata)
+	}
+	s.handle(mux, "/aggregate", api.Append(s.withMaintenance), http.HandlerFunc(s.aggregateHandler))
+	s.handle(mux, "/uptime", readOnly, http.HandlerFunc(uptimeHandler))
+	s.handle(mux, "/readyz", readOnly, http.HandlerFunc(s.readyzHandler))
+	if o.whoami {
+		s.handle(mux, "/whoami", readOnly, http.HandlerFunc(s.whoamiHandler))
+	}
+	if o.expvar {
+		debug := readOnly.Append(basicAuth(o.expvarUser, o.expvarPassword))
+		s.handle(mux, "/debug/vars", debug, http.HandlerFunc(metricsHandler))
+	}
+	if o.adminFlags != nil {
+		admin := readOnly.Append(basicAuth(o.adminUser, o.adminPassword))
+		s.handle(mux, "/admin/config", admin, http.HandlerFunc(s.configHandler))
+		adminWrite := NewChain(allowMethods(http.MethodGet, http.MethodHead, http.MethodPost), basicAuth(o.adminUser, o.adminPassword))
+		s.handle(mux, "/admin/maintenance", adminWrite, http.HandlerFunc(s.maintenanceHandler))
+		s.handle(mux, "/debug/echo", adminWrite, http.HandlerFunc(echoHandler))
+		if o.chaos {
+			s.handle(mux, "/admin/chaos", adminWrite, http.HandlerFunc(s.chaosHandler))
+		}
+	}
+	for path := range o.routeTimeouts {
+		if !s.routes[path] {
+			errs = append(errs, fmt.Errorf("route timeout configured for unknown route %s", path))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	chain := NewChain(withRequestID)
+	if o.basePath != "" {
+		chain = chain.Append(withBasePath(o.basePath, o.serveOutside))
+	}
+	if o.chaos {
+		chain = chain.Append(s.withFaults)
+	}
+	s.handler = chain.Then(mux)
+	s.httpServer = &http.Server{Addr: o.addr, Handler: s.handler}
+
+	if o.backendIP != "" {
+		o.logger.Printf("Pinning backend connections to %s\n", o.backendIP)
+	}
+	if o.basePath != "" {
+		o.logger.Printf("Serving routes under %s/\n", o.basePath)
+	}
+	if o.fallback != nil {
+		o.logger.Println("Failing over to a fallback backend when the primary fails")
+	}
+	if initialURL == nil {
+		o.logger.Println("WARNING: no backend URL configured, /aggregate will respond with 502")
+	}
+	if o.expvar {
+		o.logger.Println("Serving expvar metrics on /debug/vars")
+	}
+	if o.chaos {
+		o.logger.Printf("WARNING: fault injection is enabled with %d rule(s)\n", len(o.faultRules))
+	}
+	if s.maintenance.active() {
+		o.logger.Println("WARNING: maintenance mode is active, /aggregate will respond with 503")
+	}
+	return s, nil
+}
+
+// checkNamesOf mengembalikan nama semua pemeriksaan tambahan sesuai urutan registrasi
+func checkNamesOf(checks []namedCheck, urls []readinessURL) []string {
+	names := make([]string, 0, len(checks)+len(urls))
+	for _, c := range checks {
+		names = append(names, c.name)
+	}
+	for _, u := range urls {
+		names = append(names, u.name)
+	}
+	return names
+}
+
+// handle memasang handler di mux dengan chain route ditambah batas waktu route tersebut
+func (s *Server) handle(mux *http.ServeMux, path string, chain Chain, h http.Handler) {
+	mux.Handle(path, s.route(path, chain, h))
+}
+
+// route membungkus h dengan chain dan batas waktu route path, lalu mencatat path sebagai route yang dikenal
+func (s *Server) route(path string, chain Chain, h http.Handler) http.Handler {
+	timeout := s.opts.requestTimeout
+	if override, ok := s.opts.routeTimeouts[path]; ok {
+		timeout = override
+	}
+	if s.routes == nil {
+		s.routes = make(map[string]bool)
+	}
+	s.routes[path] = true
+	return chain.Append(withTimeout(timeout)).Then(h)
+}
+
+// ServeHTTP membuat Server bisa dipakai langsung sebagai http.Handler, mis. dengan httptest
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+// Run menjalankan server sampai ctx dibatalkan, lalu melakukan shutdown dengan graceful
+func (s *Server) Run(ctx context.Context) error {
+	ln, inherited, err := listen(s.opts.addr)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.listener = ln
+	s.mu.Unlock()
+	addr := ln.Addr().String()
+	s.boundAddr.Store(&addr)
+
+	if inherited {
+		s.opts.logger.Printf("Starting client API server on inherited listener %s\n", ln.Addr())
+	} else {
+		s.opts.logger.Printf("Starting client API server on %s\n", s.opts.addr)
+	}
+	if err := notifyReady(); err != nil {
+		s.opts.logger.Println("Error while notifying the previous process:", err)
+	}
+
+	if s.opts.maintenanceFile != "" {
+		go s.watchMaintenanceFile(ctx)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.httpServer.Serve(tcpOptionsListener{Listener: ln, opts: s.opts.tcp, logger: s.opts.logger})
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		return s.Shutdown(shutdownCtx)
+	}
+}
+
+// Shutdown menghentikan server dan menunggu request yang sedang berjalan selesai
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closing = true
+	s.mu.Unlock()
+	return s.httpServer.Shutdown(ctx)
+}
+
+// Reload memuat ulang URL backend; nilai lama dipertahankan jika gagal dan
+// tidak ada yang dilakukan jika shutdown sudah dimulai
+func (s *Server) Reload() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closing {
+		return
+	}
+
+	u, err := s.opts.loadBackend()
+	if err == nil {
+		err = checkPinnedIP(u, s.opts.backendIP)
+	}
+	if err != nil {
+		s.opts.logger.Println("Error while reloading backend URL, keeping previous value:", err)
+		return
+	}
+	s.backend.Store(u)
+	if u == nil {
+		s.opts.logger.Println("WARNING: reloaded an empty backend URL, /aggregate will respond with 502")
+		return
+	}
+	s.opts.logger.Println("Reloaded backend URL")
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// newTestServer membuat Server dengan logger yang dibuang; tidak ada backend kecuali diberikan lewat opts
+func newTestServer(t *testing.T, opts ...Option) *Server {
+	t.Helper()
+	defaults := []Option{WithBackend(""), WithLogger(log.New(io.Discard, "", 0))}
+	srv, err := NewServer(append(defaults, opts...)...)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	return srv
+}
+
+// serve menjalankan satu request ke srv dan mengembalikan hasilnya
+func serve(srv http.Handler, method, target string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestProxyLanding(t *testing.T) {
+	srv := newTestServer(t, WithBackend("http://backend.example/uuid"), WithProxyLanding(true))
+	tests := []struct {
+		method, target string
+		status         int
+	}{
+		{http.MethodGet, "/", http.StatusOK},
+		{http.MethodHead, "/", http.StatusOK},
+		{http.MethodPost, "/", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/anything", http.StatusNotFound},
+		{http.MethodPost, "/anything", http.StatusNotFound},
+		{http.MethodOptions, "/anything", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := serve(srv, tt.method, tt.target, nil)
+		if rec.Code != tt.status {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.target, rec.Code, tt.status)
+		}
+		if tt.status == http.StatusNotFound && rec.Header().Get("Allow") != "" {
+			t.Errorf("%s %s: unexpected Allow header %q", tt.method, tt.target, rec.Header().Get("Allow"))
+		}
+	}
+
+	rec := serve(srv, http.MethodGet, "/", nil)
+	if !strings.Contains(rec.Body.String(), `"backend":"http://backend.example/uuid"`) {
+		t.Errorf("landing body = %s, want the backend URL", rec.Body.String())
+	}
+}
+
+func TestExpvarRequiresCredentials(t *testing.T) {
+	for _, creds := range [][2]string{{"", ""}, {"user", ""}, {"", "pass"}} {
+		if _, err := NewServer(WithBackend(""), WithExpvar(creds[0], creds[1])); err == nil {
+			t.Errorf("WithExpvar(%q, %q): NewServer succeeded, want an error", creds[0], creds[1])
+		}
+	}
+}
+
+// debugVars mengambil /debug/vars dari srv dengan basic auth user/pass
+func debugVars(t *testing.T, srv http.Handler) map[string]json.RawMessage {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/debug/vars", nil)
+	req.SetBasicAuth("user", "pass")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("with auth: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var vars map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &vars); err != nil {
+		t.Fatalf("decoding /debug/vars: %v\n%s", err, rec.Body.String())
+	}
+	return vars
+}
+
+// counter membaca variabel expvar bertipe Int dari hasil debugVars
+func counter(t *testing.T, vars map[string]json.RawMessage, name string) int64 {
+	t.Helper()
+	var n int64
+	if err := json.Unmarshal(vars[name], &n); err != nil {
+		t.Fatalf("%s = %s: %v", name, vars[name], err)
+	}
+	return n
+}
+
+func TestExpvarPublishesOnlyAppVars(t *testing.T) {
+	backend := statusBackend(t, http.StatusOK)
+	srv := newTestServer(t, WithBackend(backend.URL), WithExpvar("user", "pass"))
+
+	if rec := serve(srv, http.MethodGet, "/debug/vars", nil); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("without auth: status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	vars := debugVars(t, srv)
+	for _, name := range []string{"cmdline", "memstats"} {
+		if _, ok := vars[name]; ok {
+			t.Errorf("/debug/vars exposes %s", name)
+		}
+	}
+	for _, name := range metricNames {
+		if _, ok := vars[name]; !ok {
+			t.Errorf("/debug/vars is missing %s", name)
+		}
+	}
+
+	requests, calls := counter(t, vars, "aggregate_requests"), counter(t, vars, "backend_calls")
+	aggregate(t, srv, "/aggregate?count=3")
+	vars = debugVars(t, srv)
+	if got := counter(t, vars, "aggregate_requests"); got != requests+1 {
+		t.Errorf("aggregate_requests = %d, want %d", got, requests+1)
+	}
+	if got := counter(t, vars, "backend_calls"); got != calls+3 {
+		t.Errorf("backend_calls = %d, want %d", got, calls+3)
+	}
+}
+
+// newFlagSet membuat FlagSet kosong untuk WithAdminConfig
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("test", flag.ContinueOnError)
+}
+
+func TestReloadDuringShutdown(t *testing.T) {
+	var loads atomic.Int64
+	loader := func() (*url.URL, error) {
+		n := loads.Add(1)
+		return url.Parse(fmt.Sprintf("http://backend%d.example/uuid", n))
+	}
+	srv := newTestServer(t, WithBackendLoader(loader))
+
+	// Sinyal SIGHUP dan SIGTERM yang datang bersamaan: Reload dan Shutdown berjalan paralel
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				srv.Reload()
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := srv.Shutdown(context.Background()); err != nil {
+			t.Errorf("Shutdown: %v", err)
+		}
+	}()
+	wg.Wait()
+
+	before, backend := loads.Load(), srv.backend.Load()
+	srv.Reload()
+	if got := loads.Load(); got != before {
+		t.Errorf("Reload after Shutdown called the loader (%d -> %d loads)", before, got)
+	}
+	if got := srv.backend.Load(); got != backend {
+		t.Errorf("Reload after Shutdown changed the backend from %s to %s", backend, got)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+)
+
+// tcpOptions adalah opsi socket yang diterapkan ke setiap koneksi yang diterima
+type tcpOptions struct {
+	noDelay     bool
+	readBuffer  int
+	writeBuffer int
+}
+
+// tcpOptionsListener menerapkan tcpOptions pada setiap koneksi dari Accept.
+//
+// Di Linux kernel menggandakan nilai SO_RCVBUF/SO_SNDBUF yang diminta dan
+// membatasinya dengan net.core.rmem_max/wmem_max, jadi ukuran efektif bisa
+// berbeda dari nilai flag. Nilai 0 berarti memakai default OS.
+type tcpOptionsListener struct {
+	net.Listener
+	opts   tcpOptions
+	logger *log.Logger
+}
+
+// Accept menerima koneksi lalu menerapkan opsi socket. Koneksi yang opsinya gagal
+// diterapkan (mis. client sudah reset, EINVAL di BSD/macOS) ditutup dan Accept
+// lanjut ke koneksi berikutnya, karena error dari Accept menghentikan http.Server.
+func (l tcpOptionsListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		tcpConn, ok := conn.(*net.TCPConn)
+		if !ok {
+			return conn, nil
+		}
+
+		if err := applyTCPOptions(tcpConn, l.opts); err != nil {
+			l.logger.Printf("Error while applying TCP options to %s, closing connection: %s\n", conn.RemoteAddr(), err)
+			conn.Close()
+			continue
+		}
+		return conn, nil
+	}
+}
+
+// applyTCPOptions mengatur TCP_NODELAY dan ukuran buffer pada satu koneksi
+func applyTCPOptions(conn *net.TCPConn, opts tcpOptions) error {
+	if err := conn.SetNoDelay(opts.noDelay); err != nil {
+		return fmt.Errorf("setting TCP_NODELAY: %w", err)
+	}
+	if opts.readBuffer > 0 {
+		if err := conn.SetReadBuffer(opts.readBuffer); err != nil {
+			return fmt.Errorf("setting receive buffer: %w", err)
+		}
+	}
+	if opts.writeBuffer > 0 {
+		if err := conn.SetWriteBuffer(opts.writeBuffer); err != nil {
+			return fmt.Errorf("setting send buffer: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+//go:build linux
+
+package main
+
+import (
+	"io"
+	"log"
+	"net"
+	"syscall"
+	"testing"
+)
+
+// sockoptInt membaca opsi socket integer dari koneksi TCP
+func sockoptInt(t *testing.T, conn net.Conn, level, opt int) int {
+	t.Helper()
+	raw, err := conn.(*net.TCPConn).SyscallConn()
+	if err != nil {
+		t.Fatalf("SyscallConn: %v", err)
+	}
+	var value int
+	var sockErr error
+	if err := raw.Control(func(fd uintptr) {
+		value, sockErr = syscall.GetsockoptInt(int(fd), level, opt)
+	}); err != nil {
+		t.Fatalf("Control: %v", err)
+	}
+	if sockErr != nil {
+		t.Fatalf("getsockopt: %v", sockErr)
+	}
+	return value
+}
+
+func TestTCPOptionsListenerAppliesOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts tcpOptions
+	}{
+		{"nodelay", tcpOptions{noDelay: true}},
+		{"delay with buffers", tcpOptions{noDelay: false, readBuffer: 64 << 10, writeBuffer: 64 << 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ln.Close()
+			l := tcpOptionsListener{Listener: ln, opts: tt.opts, logger: log.New(io.Discard, "", 0)}
+
+			client, err := net.Dial("tcp", ln.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+			conn, err := l.Accept()
+			if err != nil {
+				t.Fatalf("Accept: %v", err)
+			}
+			defer conn.Close()
+
+			noDelay := sockoptInt(t, conn, syscall.IPPROTO_TCP, syscall.TCP_NODELAY) != 0
+			if noDelay != tt.opts.noDelay {
+				t.Errorf("TCP_NODELAY = %t, want %t", noDelay, tt.opts.noDelay)
+			}
+			// Linux menggandakan nilai yang diminta, jadi cukup dipastikan tidak lebih kecil
+			if tt.opts.readBuffer > 0 {
+				if got := sockoptInt(t, conn, syscall.SOL_SOCKET, syscall.SO_RCVBUF); got < tt.opts.readBuffer {
+					t.Errorf("SO_RCVBUF = %d, want at least %d", got, tt.opts.readBuffer)
+				}
+			}
+			if tt.opts.writeBuffer > 0 {
+				if got := sockoptInt(t, conn, syscall.SOL_SOCKET, syscall.SO_SNDBUF); got < tt.opts.writeBuffer {
+					t.Errorf("SO_SNDBUF = %d, want at least %d", got, tt.opts.writeBuffer)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"io"
+	"log"
+	"net"
+	"testing"
+)
+
+// queueListener mengembalikan koneksi dari conns secara berurutan
+type queueListener struct {
+	net.Listener
+	conns []net.Conn
+}
+
+func (l *queueListener) Accept() (net.Conn, error) {
+	conn := l.conns[0]
+	l.conns = l.conns[1:]
+	return conn, nil
+}
+
+// tcpPair membuat koneksi TCP loopback dan mengembalikan sisi server-nya
+func tcpPair(t *testing.T) net.Conn {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	client, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestTCPOptionsListenerSkipsFailedConn(t *testing.T) {
+	broken := tcpPair(t)
+	broken.Close() // setsockopt pada koneksi yang sudah ditutup selalu gagal
+	good := tcpPair(t)
+
+	l := tcpOptionsListener{
+		Listener: &queueListener{conns: []net.Conn{broken, good}},
+		opts:     tcpOptions{noDelay: true},
+		logger:   log.New(io.Discard, "", 0),
+	}
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatalf("Accept returned %v, want the next connection", err)
+	}
+	if conn != good {
+		t.Fatalf("Accept returned %v, want the second connection", conn.RemoteAddr())
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// startTime adalah waktu proses dimulai
+var startTime = time.Now()
+
+// UptimeResponse struct untuk respons /uptime
+type UptimeResponse struct {
+	Uptime        string  `json:"uptime"`
+	UptimeSeconds float64 `json:"uptime_seconds"`
+	StartedAt     string  `json:"started_at"`
+}
+
+// uptimeHandler mengembalikan lama proses sudah berjalan
+func uptimeHandler(w http.ResponseWriter, r *http.Request) {
+	uptime := time.Since(startTime)
+	jsonResponse, err := json.Marshal(UptimeResponse{
+		Uptime:        uptime.Round(time.Second).String(),
+		UptimeSeconds: uptime.Seconds(),
+		StartedAt:     startTime.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonResponse)
+}
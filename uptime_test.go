@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// uptime mengambil /uptime dari srv dan men-decode respons JSON-nya
+func uptime(t *testing.T, srv http.Handler) UptimeResponse {
+	t.Helper()
+	rec := serve(srv, http.MethodGet, "/uptime", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("/uptime: status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	var response UptimeResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decoding /uptime: %v", err)
+	}
+	return response
+}
+
+func TestUptimeIncreases(t *testing.T) {
+	srv := newTestServer(t)
+	first := uptime(t, srv)
+	time.Sleep(10 * time.Millisecond)
+	second := uptime(t, srv)
+
+	if first.UptimeSeconds < 0 {
+		t.Errorf("uptime_seconds = %f, want a non-negative value", first.UptimeSeconds)
+	}
+	if second.UptimeSeconds < first.UptimeSeconds {
+		t.Errorf("uptime_seconds went from %f to %f", first.UptimeSeconds, second.UptimeSeconds)
+	}
+	startedAt, err := time.Parse(time.RFC3339, second.StartedAt)
+	if err != nil {
+		t.Fatalf("started_at %q: %v", second.StartedAt, err)
+	}
+	if startedAt.After(time.Now()) {
+		t.Errorf("started_at %s is in the future", startedAt)
+	}
+	if first.StartedAt != second.StartedAt {
+		t.Errorf("started_at changed from %s to %s", first.StartedAt, second.StartedAt)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"time"
+)
+
+// WhoamiResponse struct untuk respons /whoami
+type WhoamiResponse struct {
+	Hostname   string   `json:"hostname"`
+	Addresses  []string `json:"addresses"`
+	ListenAddr string   `json:"listen_addr"`
+	Version    string   `json:"version"`
+	Uptime     string   `json:"uptime"`
+	ClientIP   string   `json:"client_ip"`
+	RemoteAddr string   `json:"remote_addr"`
+	Host       string   `json:"host"`
+	Proto      string   `json:"proto"`
+	RequestID  string   `json:"request_id,omitempty"`
+}
+
+// whoamiHandler mengembalikan identitas instance dan request yang diterima,
+// tanpa memanggil backend
+func (s *Server) whoamiHandler(w http.ResponseWriter, r *http.Request) {
+	hostname, _ := os.Hostname()
+	clientIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		clientIP = r.RemoteAddr
+	}
+
+	response := WhoamiResponse{
+		Hostname:   hostname,
+		Addresses:  interfaceAddrs(),
+		ListenAddr: s.listenAddr(),
+		Version:    buildVersion(),
+		Uptime:     time.Since(startTime).Round(time.Second).String(),
+		ClientIP:   clientIP,
+		RemoteAddr: r.RemoteAddr,
+		Host:       r.Host,
+		Proto:      r.Proto,
+		RequestID:  requestID(r.Context()),
+	}
+	jsonResponse, err := json.Marshal(response)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(jsonResponse)
+}
+
+// listenAddr mengembalikan alamat listener yang sedang dipakai, atau alamat
+// yang dikonfigurasi jika server belum listen. Tidak memakai s.mu karena Restart
+// bisa memegangnya lama.
+func (s *Server) listenAddr() string {
+	if addr := s.boundAddr.Load(); addr != nil {
+		return *addr
+	}
+	return s.opts.addr
+}
+
+// interfaceAddrs mengembalikan alamat IP semua interface selain loopback
+func interfaceAddrs() []string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	var ips []string
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() {
+			ips = append(ips, ipNet.IP.String())
+		}
+	}
+	return ips
+}
+
+// buildVersion mengembalikan versi module atau revisi VCS dari build info
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+	return info.Main.Version
+}
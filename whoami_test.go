@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestWhoami(t *testing.T) {
+	srv := newTestServer(t)
+	header := http.Header{}
+	header.Set(requestIDHeader, "whoami-1")
+	rec := serve(srv, http.MethodGet, "http://fe.example/whoami", header)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &fields); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"hostname", "addresses", "listen_addr", "version", "uptime", "client_ip", "remote_addr", "host", "proto", "request_id"} {
+		if _, ok := fields[name]; !ok {
+			t.Errorf("field %q is missing from %s", name, rec.Body.String())
+		}
+	}
+
+	var response WhoamiResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	hostname, _ := os.Hostname()
+	// httptest.NewRequest memakai RemoteAddr 192.0.2.1:1234
+	for _, tt := range []struct{ name, got, want string }{
+		{"hostname", response.Hostname, hostname},
+		{"client_ip", response.ClientIP, "192.0.2.1"},
+		{"remote_addr", response.RemoteAddr, "192.0.2.1:1234"},
+		{"host", response.Host, "fe.example"},
+		{"proto", response.Proto, "HTTP/1.1"},
+		{"request_id", response.RequestID, "whoami-1"},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestWhoamiDisabled(t *testing.T) {
+	srv := newTestServer(t, WithWhoami(false))
+	if rec := serve(srv, http.MethodGet, "/whoami", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}